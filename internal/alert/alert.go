@@ -0,0 +1,134 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"time"
+)
+
+const (
+	ActionCommand = "command"
+	ActionFile    = "file"
+	ActionWebhook = "webhook"
+)
+
+// Timeout bounds how long a single action may run
+const Timeout = 30 * time.Second
+
+// Event is the JSON document handed to an alert action
+type Event struct {
+	RuleID  string    `json:"rule_id"`
+	Pattern string    `json:"pattern"`
+	FiredAt time.Time `json:"fired_at"`
+	Feed    Feed      `json:"feed"`
+	Post    Post      `json:"post"`
+}
+
+type Feed struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type Post struct {
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	Categories  []string   `json:"categories,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// ValidateAction checks that target makes sense for action before a rule
+// is saved
+func ValidateAction(action, target string) error {
+	if target == "" {
+		return errors.New("alert target can't be empty")
+	}
+
+	switch action {
+	case ActionCommand, ActionFile:
+		return nil
+	case ActionWebhook:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("webhook target must be an http or https URL")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown action %q, expected command, file or webhook", action)
+}
+
+// Fire runs action for event. Commands are run by the shell with the event
+// on stdin, files get the event appended as one line of JSON and webhooks
+// receive it as the body of a POST request.
+func Fire(ctx context.Context, action, target string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	switch action {
+	case ActionCommand:
+		return runCommand(ctx, target, body)
+	case ActionFile:
+		return appendFile(target, body)
+	case ActionWebhook:
+		return postWebhook(ctx, target, body)
+	}
+	return fmt.Errorf("unknown action %q", action)
+}
+
+func runCommand(ctx context.Context, command string, body []byte) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(body)
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if out = bytes.TrimSpace(out); len(out) > 0 {
+		return fmt.Errorf("command failed: %w: %s", err, out)
+	}
+	return fmt.Errorf("command failed: %w", err)
+}
+
+func appendFile(path string, body []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(body, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func postWebhook(ctx context.Context, target string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("User-Agent", "gator")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %v", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testEvent = Event{
+	RuleID:  "rule-1",
+	Pattern: "gator",
+	FiredAt: time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC),
+	Feed:    Feed{Name: "Example", URL: "https://example.com/feed.xml"},
+	Post:    Post{Title: "gator 1.0 released", URL: "https://example.com/1", Categories: []string{"news"}},
+}
+
+func TestFireWebhook(t *testing.T) {
+	var got Event
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %v, want POST", r.Method)
+		}
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("body is not an event: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	if err := Fire(context.Background(), ActionWebhook, srv.URL, testEvent); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q", contentType)
+	}
+	if got.RuleID != testEvent.RuleID || got.Post.Title != testEvent.Post.Title || got.Feed.URL != testEvent.Feed.URL {
+		t.Errorf("got event %+v", got)
+	}
+}
+
+func TestFireWebhookError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	err := Fire(context.Background(), ActionWebhook, srv.URL, testEvent)
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("Fire() error = %v, want a 500 error", err)
+	}
+}
+
+func TestFireFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.jsonl")
+	for i := 0; i < 2; i++ {
+		if err := Fire(context.Background(), ActionFile, path, testEvent); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var got Event
+	if err := json.Unmarshal([]byte(lines[1]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Post.URL != testEvent.Post.URL {
+		t.Errorf("got post URL %q", got.Post.URL)
+	}
+}
+
+func TestFireCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stdin.json")
+	if err := Fire(context.Background(), ActionCommand, "cat > "+path, testEvent); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Event
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("command did not get the event on stdin: %v", err)
+	}
+
+	err = Fire(context.Background(), ActionCommand, "echo nope >&2; exit 3", testEvent)
+	if err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("Fire() error = %v, want the command's output", err)
+	}
+}
+
+func TestValidateAction(t *testing.T) {
+	tests := []struct {
+		action string
+		target string
+		ok     bool
+	}{
+		{ActionCommand, "notify-send gator", true},
+		{ActionFile, "/tmp/alerts.jsonl", true},
+		{ActionWebhook, "https://example.com/hook", true},
+		{ActionWebhook, "ftp://example.com/hook", false},
+		{ActionWebhook, "not a url", false},
+		{ActionFile, "", false},
+		{"email", "me@example.com", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateAction(tt.action, tt.target)
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateAction(%q, %q) = %v, want ok %v", tt.action, tt.target, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// Prefix is the path the API is served under
+const Prefix = "/api/v1"
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+	keyPrefix       = "gator_"
+)
+
+//go:embed openapi.yaml
+var openAPIDocument []byte
+
+// AddFeedFunc adds or follows the feed at url for user, the same way the
+// addfeed and follow commands do
+type AddFeedFunc func(ctx context.Context, user database.User, name, url string) (database.Feed, error)
+
+// Store is the part of the database the API uses. *database.Queries
+// implements it.
+type Store interface {
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (database.ApiKey, error)
+	TouchAPIKey(ctx context.Context, arg database.TouchAPIKeyParams) error
+	GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error)
+	GetUsersPage(ctx context.Context, arg database.GetUsersPageParams) ([]database.User, error)
+	GetFeedsPage(ctx context.Context, arg database.GetFeedsPageParams) ([]database.GetFeedsPageRow, error)
+	GetFeedByUrl(ctx context.Context, url string) (database.Feed, error)
+	GetFeedFollowsPageForUser(ctx context.Context, arg database.GetFeedFollowsPageForUserParams) ([]database.GetFeedFollowsPageForUserRow, error)
+	CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error)
+	DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) (database.FeedFollow, error)
+	GetPostsPageForUser(ctx context.Context, arg database.GetPostsPageForUserParams) ([]database.GetPostsPageForUserRow, error)
+	GetPostWithStateForUser(ctx context.Context, arg database.GetPostWithStateForUserParams) (database.GetPostWithStateForUserRow, error)
+	SetPostRead(ctx context.Context, arg database.SetPostReadParams) error
+	SetPostStarred(ctx context.Context, arg database.SetPostStarredParams) error
+}
+
+// Server serves the REST API. Every endpoint except the OpenAPI document
+// needs an API key sent as "Authorization: Bearer <key>".
+type Server struct {
+	db      Store
+	addFeed AddFeedFunc
+	mux     *http.ServeMux
+}
+
+func New(db Store, addFeed AddFeedFunc) *Server {
+	srv := &Server{
+		db:      db,
+		addFeed: addFeed,
+		mux:     http.NewServeMux(),
+	}
+
+	srv.mux.HandleFunc("GET "+Prefix+"/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(openAPIDocument)
+	})
+
+	srv.handle("GET /users", srv.listUsers)
+	srv.handle("GET /users/me", srv.getCurrentUser)
+	srv.handle("GET /feeds", srv.listFeeds)
+	srv.handle("POST /feeds", srv.createFeed)
+	srv.handle("GET /follows", srv.listFollows)
+	srv.handle("POST /follows", srv.createFollow)
+	srv.handle("DELETE /follows/{feed_id}", srv.deleteFollow)
+	srv.handle("GET /posts", srv.listPosts)
+	srv.handle("GET /posts/{id}", srv.getPost)
+	srv.handle("PATCH /posts/{id}", srv.updatePost)
+
+	return srv
+}
+
+func (srv *Server) handle(pattern string, h func(w http.ResponseWriter, r *http.Request, user database.User)) {
+	method, path, _ := strings.Cut(pattern, " ")
+	srv.mux.Handle(method+" "+Prefix+path, srv.requireKey(h))
+}
+
+// ServeHTTP routes API requests, answering unknown paths and methods with
+// the same JSON errors as the endpoints
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// mux.Handler only finds the handler, serving through the mux is what
+	// sets the path values
+	h, pattern := srv.mux.Handler(r)
+	if pattern != "" {
+		srv.mux.ServeHTTP(w, r)
+		return
+	}
+
+	// The mux's own handler decides between 404 and 405 and sets Allow
+	rec := &statusRecorder{header: http.Header{}}
+	h.ServeHTTP(rec, r)
+	if allow := rec.header.Get("Allow"); allow != "" {
+		w.Header().Set("Allow", allow)
+	}
+	if rec.status == http.StatusMethodNotAllowed {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	writeError(w, http.StatusNotFound, "not_found", "no such endpoint")
+}
+
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (rec *statusRecorder) Header() http.Header         { return rec.header }
+func (rec *statusRecorder) Write(b []byte) (int, error) { return len(b), nil }
+func (rec *statusRecorder) WriteHeader(status int)      { rec.status = status }
+
+// NewKey returns a new API key and the hash stored in its place
+func NewKey() (string, string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key := keyPrefix + hex.EncodeToString(b)
+	return key, HashKey(key), nil
+}
+
+// HashKey returns the hex encoded SHA-256 of key. Keys are random, so a
+// plain hash is enough to keep them out of the database.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ErrInvalidKey is returned by UserForKey for unknown API keys
+var ErrInvalidKey = errors.New("invalid API key")
+
+// UserForKey returns the user an API key belongs to and records that the
+// key was used
+func UserForKey(ctx context.Context, db Store, key string) (database.User, error) {
+	apiKey, err := db.GetAPIKeyByHash(ctx, HashKey(strings.TrimSpace(key)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, ErrInvalidKey
+	}
+	if err != nil {
+		return database.User{}, err
+	}
+
+	user, err := db.GetUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	// The last used time is only informational, so failing to record it
+	// doesn't fail the request
+	err = db.TouchAPIKey(ctx, database.TouchAPIKeyParams{
+		ID:         apiKey.ID,
+		LastUsedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		log.Printf("could not record use of API key %v: %v", apiKey.ID, err)
+	}
+	return user, nil
+}
+
+func (srv *Server) requireKey(h func(w http.ResponseWriter, r *http.Request, user database.User)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || key == "" {
+			writeError(w, http.StatusUnauthorized, "unauthorized", "missing API key")
+			return
+		}
+
+		user, err := UserForKey(r.Context(), srv.db, key)
+		if errors.Is(err, ErrInvalidKey) {
+			writeError(w, http.StatusUnauthorized, "unauthorized", err.Error())
+			return
+		}
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		h(w, r, user)
+	})
+}
+
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, errorBody{Error: errorDetail{Code: code, Message: message}})
+}
+
+// writeInternalError logs err and answers with a generic message, so
+// database errors don't reach clients
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("api: %v %v: %v", r.Method, r.URL.Path, err)
+	writeError(w, http.StatusInternalServerError, "internal", "internal server error")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// readJSON decodes a request body into v, rejecting unknown fields
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// page is the limit and offset a list request asked for
+type page struct {
+	Limit  int32
+	Offset int32
+}
+
+type pagination struct {
+	Limit      int32  `json:"limit"`
+	Offset     int32  `json:"offset"`
+	NextOffset *int32 `json:"next_offset"`
+}
+
+type listBody[T any] struct {
+	Data       []T        `json:"data"`
+	Pagination pagination `json:"pagination"`
+}
+
+func readPage(w http.ResponseWriter, r *http.Request) (page, bool) {
+	p := page{Limit: defaultPageSize}
+	query := r.URL.Query()
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			writeError(w, http.StatusBadRequest, "bad_request", "limit must be between 1 and "+strconv.Itoa(maxPageSize))
+			return p, false
+		}
+		p.Limit = int32(limit)
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			writeError(w, http.StatusBadRequest, "bad_request", "offset must be a non-negative integer")
+			return p, false
+		}
+		p.Offset = int32(offset)
+	}
+	return p, true
+}
+
+// writeList writes one page of items. Queries fetch one row more than the
+// limit, so a full page plus one means there is a next page.
+func writeList[T any](w http.ResponseWriter, p page, items []T) {
+	body := listBody[T]{Data: items, Pagination: pagination{Limit: p.Limit, Offset: p.Offset}}
+	if len(items) > int(p.Limit) {
+		body.Data = items[:p.Limit]
+		next := p.Offset + p.Limit
+		body.Pagination.NextOffset = &next
+	}
+	if body.Data == nil {
+		body.Data = []T{}
+	}
+	writeJSON(w, http.StatusOK, body)
+}
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// fakeStore keeps users, API keys and posts in memory. Posts are only
+// visible to the users listed for them, the way the SQL queries scope
+// posts to followed feeds.
+type fakeStore struct {
+	users   []database.User
+	keys    map[string]database.ApiKey
+	posts   map[uuid.UUID]fakePost
+	touched map[uuid.UUID]bool
+
+	// failWith makes the listing queries fail, and touchErr TouchAPIKey
+	failWith error
+	touchErr error
+}
+
+type fakePost struct {
+	row     database.GetPostWithStateForUserRow
+	visible map[uuid.UUID]bool
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		keys:    map[string]database.ApiKey{},
+		posts:   map[uuid.UUID]fakePost{},
+		touched: map[uuid.UUID]bool{},
+	}
+}
+
+// addUser adds a user with an API key and returns both
+func (f *fakeStore) addUser(t *testing.T, name string) (database.User, string) {
+	t.Helper()
+	user := database.User{ID: uuid.New(), CreatedAt: time.Now(), Name: name}
+	f.users = append(f.users, user)
+
+	key, hash, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.keys[hash] = database.ApiKey{ID: uuid.New(), UserID: user.ID, Name: "test", KeyHash: hash}
+	return user, key
+}
+
+func (f *fakeStore) addPost(title string, visibleTo ...uuid.UUID) uuid.UUID {
+	post := fakePost{
+		row: database.GetPostWithStateForUserRow{
+			ID:       uuid.New(),
+			Title:    sql.NullString{String: title, Valid: true},
+			FeedID:   uuid.NullUUID{UUID: uuid.New(), Valid: true},
+			FeedName: "Feed",
+		},
+		visible: map[uuid.UUID]bool{},
+	}
+	for _, id := range visibleTo {
+		post.visible[id] = true
+	}
+	f.posts[post.row.ID] = post
+	return post.row.ID
+}
+
+func (f *fakeStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (database.ApiKey, error) {
+	key, ok := f.keys[keyHash]
+	if !ok {
+		return database.ApiKey{}, sql.ErrNoRows
+	}
+	return key, nil
+}
+
+func (f *fakeStore) TouchAPIKey(ctx context.Context, arg database.TouchAPIKeyParams) error {
+	if f.touchErr != nil {
+		return f.touchErr
+	}
+	f.touched[arg.ID] = true
+	return nil
+}
+
+func (f *fakeStore) GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error) {
+	for _, user := range f.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (f *fakeStore) GetUsersPage(ctx context.Context, arg database.GetUsersPageParams) ([]database.User, error) {
+	if f.failWith != nil {
+		return nil, f.failWith
+	}
+	users := f.users[min(int(arg.Offset), len(f.users)):]
+	return users[:min(int(arg.Limit), len(users))], nil
+}
+
+func (f *fakeStore) GetFeedsPage(ctx context.Context, arg database.GetFeedsPageParams) ([]database.GetFeedsPageRow, error) {
+	return nil, f.failWith
+}
+
+func (f *fakeStore) GetFeedByUrl(ctx context.Context, url string) (database.Feed, error) {
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (f *fakeStore) GetFeedFollowsPageForUser(ctx context.Context, arg database.GetFeedFollowsPageForUserParams) ([]database.GetFeedFollowsPageForUserRow, error) {
+	return nil, f.failWith
+}
+
+func (f *fakeStore) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	return database.CreateFeedFollowRow{}, errors.New("not implemented")
+}
+
+func (f *fakeStore) DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) (database.FeedFollow, error) {
+	return database.FeedFollow{}, sql.ErrNoRows
+}
+
+func (f *fakeStore) GetPostsPageForUser(ctx context.Context, arg database.GetPostsPageForUserParams) ([]database.GetPostsPageForUserRow, error) {
+	if f.failWith != nil {
+		return nil, f.failWith
+	}
+	var rows []database.GetPostsPageForUserRow
+	for _, post := range f.posts {
+		if post.visible[arg.UserID.UUID] {
+			rows = append(rows, database.GetPostsPageForUserRow(post.row))
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeStore) GetPostWithStateForUser(ctx context.Context, arg database.GetPostWithStateForUserParams) (database.GetPostWithStateForUserRow, error) {
+	post, ok := f.posts[arg.PostID]
+	if !ok || !post.visible[arg.UserID.UUID] {
+		return database.GetPostWithStateForUserRow{}, sql.ErrNoRows
+	}
+	return post.row, nil
+}
+
+func (f *fakeStore) SetPostRead(ctx context.Context, arg database.SetPostReadParams) error {
+	post := f.posts[arg.PostID]
+	post.row.Read = arg.Read
+	f.posts[arg.PostID] = post
+	return nil
+}
+
+func (f *fakeStore) SetPostStarred(ctx context.Context, arg database.SetPostStarredParams) error {
+	post := f.posts[arg.PostID]
+	post.row.Starred = arg.Starred
+	f.posts[arg.PostID] = post
+	return nil
+}
+
+func do(srv *Server, method, target, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.NewDecoder(rec.Body).Decode(&v); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	return v
+}
+
+func TestRequiresKey(t *testing.T) {
+	store := newFakeStore()
+	alice, key := store.addUser(t, "alice")
+	srv := New(store, nil)
+
+	tests := []struct {
+		name    string
+		key     string
+		message string
+	}{
+		{"missing key", "", "missing API key"},
+		{"unknown key", "gator_unknown", "invalid API key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(srv, "GET", Prefix+"/users/me", tt.key, "")
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+			body := decode[errorBody](t, rec)
+			if body.Error.Code != "unauthorized" || body.Error.Message != tt.message {
+				t.Errorf("error %+v, want unauthorized %q", body.Error, tt.message)
+			}
+		})
+	}
+
+	rec := do(srv, "GET", Prefix+"/users/me", key, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /users/me: status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := decode[userJSON](t, rec); got.ID != alice.ID || got.Name != "alice" {
+		t.Errorf("GET /users/me = %+v, want alice", got)
+	}
+	if !store.touched[store.keys[HashKey(key)].ID] {
+		t.Error("the key's last use was not recorded")
+	}
+}
+
+func TestTouchFailureDoesNotFailRequest(t *testing.T) {
+	store := newFakeStore()
+	_, key := store.addUser(t, "alice")
+	store.touchErr = errors.New("pq: deadlock detected")
+	srv := New(store, nil)
+
+	if rec := do(srv, "GET", Prefix+"/users/me", key, ""); rec.Code != http.StatusOK {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestInternalErrorsNotExposed(t *testing.T) {
+	store := newFakeStore()
+	_, key := store.addUser(t, "alice")
+	store.failWith = errors.New(`pq: relation "users" does not exist`)
+	srv := New(store, nil)
+
+	for _, path := range []string{"/users", "/feeds", "/follows", "/posts"} {
+		rec := do(srv, "GET", Prefix+path, key, "")
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("GET %v: status %d, want %d", path, rec.Code, http.StatusInternalServerError)
+			continue
+		}
+		body := decode[errorBody](t, rec)
+		if body.Error.Code != "internal" || body.Error.Message != "internal server error" {
+			t.Errorf("GET %v: error %+v, want a generic internal error", path, body.Error)
+		}
+	}
+}
+
+func TestPagination(t *testing.T) {
+	store := newFakeStore()
+	_, key := store.addUser(t, "alice")
+	store.addUser(t, "bob")
+	store.addUser(t, "carol")
+	srv := New(store, nil)
+
+	rec := do(srv, "GET", Prefix+"/users?limit=2", key, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusOK)
+	}
+	first := decode[listBody[userJSON]](t, rec)
+	if len(first.Data) != 2 || first.Pagination.NextOffset == nil || *first.Pagination.NextOffset != 2 {
+		t.Fatalf("first page: %d users, pagination %+v, want 2 users and next_offset 2", len(first.Data), first.Pagination)
+	}
+
+	rec = do(srv, "GET", Prefix+"/users?limit=2&offset=2", key, "")
+	last := decode[listBody[userJSON]](t, rec)
+	if len(last.Data) != 1 || last.Data[0].Name != "carol" || last.Pagination.NextOffset != nil {
+		t.Errorf("last page: %+v, want only carol and no next_offset", last)
+	}
+
+	for _, query := range []string{"limit=0", "limit=201", "limit=x", "offset=-1"} {
+		if rec := do(srv, "GET", Prefix+"/users?"+query, key, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET /users?%v: status %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestPostsScopedToUser(t *testing.T) {
+	store := newFakeStore()
+	alice, aliceKey := store.addUser(t, "alice")
+	_, bobKey := store.addUser(t, "bob")
+	postID := store.addPost("Hello", alice.ID)
+	srv := New(store, nil)
+
+	rec := do(srv, "GET", Prefix+"/posts", bobKey, "")
+	if got := decode[listBody[postJSON]](t, rec); len(got.Data) != 0 {
+		t.Errorf("bob sees %d posts, want 0", len(got.Data))
+	}
+	if rec := do(srv, "GET", Prefix+"/posts/"+postID.String(), bobKey, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("bob GET post: status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := do(srv, "PATCH", Prefix+"/posts/"+postID.String(), bobKey, `{"read":true}`); rec.Code != http.StatusNotFound {
+		t.Errorf("bob PATCH post: status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	rec = do(srv, "PATCH", Prefix+"/posts/"+postID.String(), aliceKey, `{"starred":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("alice PATCH post: status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	got := decode[postJSON](t, rec)
+	if !got.Starred || got.Read || got.Title != "Hello" {
+		t.Errorf("PATCH starred = %+v, want starred and still unread", got)
+	}
+	if row := store.posts[postID].row; !row.Starred || row.Read {
+		t.Errorf("stored state read=%v starred=%v, want only starred", row.Read, row.Starred)
+	}
+
+	rec = do(srv, "PATCH", Prefix+"/posts/"+postID.String(), aliceKey, `{"seen":true}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("PATCH with an unknown field: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestUnknownEndpoints(t *testing.T) {
+	srv := New(newFakeStore(), nil)
+
+	rec := do(srv, "GET", Prefix+"/nothing", "", "")
+	if rec.Code != http.StatusNotFound || decode[errorBody](t, rec).Error.Code != "not_found" {
+		t.Errorf("GET unknown path: status %d, want a JSON 404", rec.Code)
+	}
+
+	rec = do(srv, "PUT", Prefix+"/users", "", "")
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") == "" {
+		t.Errorf("PUT /users: status %d Allow %q, want 405 with Allow", rec.Code, rec.Header().Get("Allow"))
+	}
+	if decode[errorBody](t, rec).Error.Code != "method_not_allowed" {
+		t.Error("405 response is not a JSON error")
+	}
+}
@@ -0,0 +1,406 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/google/uuid"
+)
+
+type userJSON struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type feedJSON struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	SiteURL       *string    `json:"site_url"`
+	Description   *string    `json:"description"`
+	ImageURL      *string    `json:"image_url"`
+	CreatedBy     *string    `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
+
+type followJSON struct {
+	ID        uuid.UUID `json:"id"`
+	FeedID    uuid.UUID `json:"feed_id"`
+	FeedName  string    `json:"feed_name"`
+	FeedURL   string    `json:"feed_url"`
+	Folder    *string   `json:"folder"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type postJSON struct {
+	ID          uuid.UUID  `json:"id"`
+	FeedID      uuid.UUID  `json:"feed_id"`
+	FeedName    string     `json:"feed_name"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description *string    `json:"description"`
+	Content     *string    `json:"content"`
+	Author      *string    `json:"author"`
+	Categories  []string   `json:"categories"`
+	CommentsURL *string    `json:"comments_url"`
+	PublishedAt *time.Time `json:"published_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Read        bool       `json:"read"`
+	Starred     bool       `json:"starred"`
+}
+
+func nullableString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func nullableTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func newUserJSON(user database.User) userJSON {
+	return userJSON{ID: user.ID, Name: user.Name, CreatedAt: user.CreatedAt}
+}
+
+func newFeedJSON(feed database.Feed, createdBy sql.NullString) feedJSON {
+	return feedJSON{
+		ID:            feed.ID,
+		Name:          feed.Name,
+		URL:           feed.Url,
+		SiteURL:       nullableString(feed.SiteUrl),
+		Description:   nullableString(feed.Description),
+		ImageURL:      nullableString(feed.ImageUrl),
+		CreatedBy:     nullableString(createdBy),
+		CreatedAt:     feed.CreatedAt,
+		LastFetchedAt: nullableTime(feed.LastFetchedAt),
+	}
+}
+
+func newPostJSON(post database.GetPostsPageForUserRow) postJSON {
+	categories := post.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+	return postJSON{
+		ID:          post.ID,
+		FeedID:      post.FeedID.UUID,
+		FeedName:    post.FeedName,
+		Title:       post.Title.String,
+		URL:         post.Url,
+		Description: nullableString(post.Description),
+		Content:     nullableString(post.Content),
+		Author:      nullableString(post.Author),
+		Categories:  categories,
+		CommentsURL: nullableString(post.CommentsUrl),
+		PublishedAt: nullableTime(post.PublishedAt),
+		CreatedAt:   post.CreatedAt,
+		Read:        post.Read,
+		Starred:     post.Starred,
+	}
+}
+
+func (srv *Server) listUsers(w http.ResponseWriter, r *http.Request, user database.User) {
+	p, ok := readPage(w, r)
+	if !ok {
+		return
+	}
+
+	users, err := srv.db.GetUsersPage(r.Context(), database.GetUsersPageParams{Limit: p.Limit + 1, Offset: p.Offset})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	items := make([]userJSON, 0, len(users))
+	for _, u := range users {
+		items = append(items, newUserJSON(u))
+	}
+	writeList(w, p, items)
+}
+
+func (srv *Server) getCurrentUser(w http.ResponseWriter, r *http.Request, user database.User) {
+	writeJSON(w, http.StatusOK, newUserJSON(user))
+}
+
+func (srv *Server) listFeeds(w http.ResponseWriter, r *http.Request, user database.User) {
+	p, ok := readPage(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := srv.db.GetFeedsPage(r.Context(), database.GetFeedsPageParams{Limit: p.Limit + 1, Offset: p.Offset})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	items := make([]feedJSON, 0, len(rows))
+	for _, row := range rows {
+		feed := database.Feed{
+			ID:            row.ID,
+			CreatedAt:     row.CreatedAt,
+			Name:          row.Name,
+			Url:           row.Url,
+			LastFetchedAt: row.LastFetchedAt,
+			SiteUrl:       row.SiteUrl,
+			Description:   row.Description,
+			ImageUrl:      row.ImageUrl,
+		}
+		items = append(items, newFeedJSON(feed, row.UserName))
+	}
+	writeList(w, p, items)
+}
+
+// createFeed adds a feed like the addfeed command and follows it. When the
+// feed is already known it is followed instead.
+func (srv *Server) createFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	var body struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+
+	feedURL, err := url.Parse(strings.TrimSpace(body.URL))
+	if err != nil || (feedURL.Scheme != "http" && feedURL.Scheme != "https") {
+		writeError(w, http.StatusBadRequest, "bad_request", "url must be an http or https URL")
+		return
+	}
+
+	feed, err := srv.addFeed(r.Context(), user, strings.TrimSpace(body.Name), feedURL.String())
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "feed_error", err.Error())
+		return
+	}
+
+	createdBy := sql.NullString{}
+	if feed.UserID.Valid {
+		if creator, err := srv.db.GetUserByID(r.Context(), feed.UserID.UUID); err == nil {
+			createdBy = sql.NullString{String: creator.Name, Valid: true}
+		}
+	}
+	writeJSON(w, http.StatusCreated, newFeedJSON(feed, createdBy))
+}
+
+func (srv *Server) listFollows(w http.ResponseWriter, r *http.Request, user database.User) {
+	p, ok := readPage(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := srv.db.GetFeedFollowsPageForUser(r.Context(), database.GetFeedFollowsPageForUserParams{
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Limit:  p.Limit + 1,
+		Offset: p.Offset,
+	})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	items := make([]followJSON, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, followJSON{
+			ID:        row.ID,
+			FeedID:    row.FeedID.UUID,
+			FeedName:  row.FeedName,
+			FeedURL:   row.FeedUrl,
+			Folder:    nullableString(row.Folder),
+			CreatedAt: row.CreatedAt,
+		})
+	}
+	writeList(w, p, items)
+}
+
+// createFollow follows a feed gator already knows, like the follow command
+func (srv *Server) createFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	var body struct {
+		FeedURL string `json:"feed_url"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+
+	feed, err := srv.db.GetFeedByUrl(r.Context(), body.FeedURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "no feed with that URL, add it with POST /feeds")
+		return
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	follow, err := srv.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    uuid.NullUUID{UUID: user.ID, Valid: true},
+		FeedID:    uuid.NullUUID{UUID: feed.ID, Valid: true},
+	})
+	if err != nil && strings.Contains(err.Error(), "unique_user_feed_pair") {
+		writeError(w, http.StatusConflict, "conflict", "already following this feed")
+		return
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, followJSON{
+		ID:        follow.ID,
+		FeedID:    feed.ID,
+		FeedName:  feed.Name,
+		FeedURL:   feed.Url,
+		CreatedAt: follow.CreatedAt,
+	})
+}
+
+func (srv *Server) deleteFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedID, err := uuid.Parse(r.PathValue("feed_id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not_found", "no such follow")
+		return
+	}
+
+	_, err = srv.db.DeleteFeedFollow(r.Context(), database.DeleteFeedFollowParams{
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		FeedID: uuid.NullUUID{UUID: feedID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "no such follow")
+		return
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (srv *Server) listPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	p, ok := readPage(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	params := database.GetPostsPageForUserParams{
+		UserID:      uuid.NullUUID{UUID: user.ID, Valid: true},
+		UnreadOnly:  query.Get("unread") == "true",
+		StarredOnly: query.Get("starred") == "true",
+		RowLimit:    p.Limit + 1,
+		RowOffset:   p.Offset,
+	}
+	if value := query.Get("feed_id"); value != "" {
+		feedID, err := uuid.Parse(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", "feed_id must be a UUID")
+			return
+		}
+		params.FeedID = uuid.NullUUID{UUID: feedID, Valid: true}
+	}
+
+	rows, err := srv.db.GetPostsPageForUser(r.Context(), params)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	items := make([]postJSON, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, newPostJSON(row))
+	}
+	writeList(w, p, items)
+}
+
+func (srv *Server) getPost(w http.ResponseWriter, r *http.Request, user database.User) {
+	post, ok := srv.loadPost(w, r, user)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, newPostJSON(post))
+}
+
+// updatePost sets the read and starred state of a post, leaving out
+// fields the body doesn't mention
+func (srv *Server) updatePost(w http.ResponseWriter, r *http.Request, user database.User) {
+	var body struct {
+		Read    *bool `json:"read"`
+		Starred *bool `json:"starred"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+
+	post, ok := srv.loadPost(w, r, user)
+	if !ok {
+		return
+	}
+
+	if body.Read != nil {
+		err := srv.db.SetPostRead(r.Context(), database.SetPostReadParams{
+			UserID:    user.ID,
+			PostID:    post.ID,
+			CreatedAt: time.Now(),
+			Read:      *body.Read,
+		})
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		post.Read = *body.Read
+	}
+	if body.Starred != nil {
+		err := srv.db.SetPostStarred(r.Context(), database.SetPostStarredParams{
+			UserID:    user.ID,
+			PostID:    post.ID,
+			CreatedAt: time.Now(),
+			Starred:   *body.Starred,
+		})
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		post.Starred = *body.Starred
+	}
+
+	writeJSON(w, http.StatusOK, newPostJSON(post))
+}
+
+// loadPost finds the post named in the path among the user's followed
+// feeds, writing an error response when it can't
+func (srv *Server) loadPost(w http.ResponseWriter, r *http.Request, user database.User) (database.GetPostsPageForUserRow, bool) {
+	postID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not_found", "no such post")
+		return database.GetPostsPageForUserRow{}, false
+	}
+
+	row, err := srv.db.GetPostWithStateForUser(r.Context(), database.GetPostWithStateForUserParams{
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		PostID: postID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "no such post")
+		return database.GetPostsPageForUserRow{}, false
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return database.GetPostsPageForUserRow{}, false
+	}
+	return database.GetPostsPageForUserRow(row), true
+}
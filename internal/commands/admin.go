@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/acehotel33/bootdev-gator/internal/auth"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"golang.org/x/term"
+)
+
+func middlewareAdmin(handler func(s *state.State, cmd Command, user database.User) error) func(*state.State, Command) error {
+	return middlewareLoggedIn(func(s *state.State, cmd Command, user database.User) error {
+		if user.Role != auth.RoleAdmin {
+			return fmt.Errorf("only admins can run %v", cmd.name)
+		}
+		return handler(s, cmd, user)
+	})
+}
+
+// takeYesFlag removes --yes from args and reports whether it was there
+func takeYesFlag(args []string) ([]string, bool) {
+	rest := make([]string, 0, len(args))
+	yes := false
+	for _, arg := range args {
+		if arg == "--yes" {
+			yes = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, yes
+}
+
+// confirm asks the user to type word before going ahead with something
+// destructive. --yes skips the question, and without a terminal it is
+// required.
+func confirm(word string, yes bool) error {
+	if yes {
+		return nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return errors.New("not confirmed, run again with --yes")
+	}
+
+	fmt.Printf("type %q to confirm: ", word)
+	input, err := stdin.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(input) != word {
+		return errors.New("cancelled")
+	}
+	return nil
+}
+
+// HandlerReset deletes every user, and with them all feeds, follows and
+// posts, after showing what will go.
+//
+//	reset [--yes]
+func HandlerReset(s *state.State, cmd Command, user database.User) error {
+	args, yes := takeYesFlag(cmd.arguments)
+	if len(args) != 0 {
+		return errors.New("invalid arguments")
+	}
+
+	summary, err := s.DB.GetResetSummary(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("this deletes %d users, %d feeds, %d follows and %d posts\n",
+		summary.UserCount, summary.FeedCount, summary.FollowCount, summary.PostCount)
+	if err := confirm("reset", yes); err != nil {
+		return err
+	}
+
+	if err := s.DB.ResetUsers(context.Background()); err != nil {
+		return err
+	}
+	if err := s.Cfg.SetSession("", ""); err != nil {
+		return err
+	}
+
+	fmt.Println("users reset")
+	return nil
+}
@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/alert"
+	"github.com/acehotel33/bootdev-gator/internal/auth"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/filter"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/google/uuid"
+)
+
+// HandlerAlert dispatches the alert rule subcommands:
+//
+//	alert add <title|description|author|category|any> <keyword|regex> <pattern> <command|file|webhook> <target> [--feed <feed_url>]
+//	alert list
+//	alert delete <rule_id>
+func HandlerAlert(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) == 0 {
+		return errors.New("invalid arguments")
+	}
+
+	args := cmd.arguments[1:]
+	switch cmd.arguments[0] {
+	case "add":
+		return addAlertRule(s, user, args)
+	case "list":
+		return listAlertRules(s, user, args)
+	case "delete":
+		return deleteAlertRule(s, user, args)
+	}
+	return fmt.Errorf("unknown alert subcommand: %v", cmd.arguments[0])
+}
+
+func addAlertRule(s *state.State, user database.User, args []string) error {
+	var positional []string
+	var feedID uuid.NullUUID
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--feed" {
+			positional = append(positional, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return errors.New("--feed needs a feed URL")
+		}
+		i++
+		feedDB, err := s.DB.GetFeedByUrl(context.Background(), args[i])
+		if err != nil {
+			return err
+		}
+		feedID = uuid.NullUUID{UUID: feedDB.ID, Valid: true}
+	}
+	if len(positional) != 5 {
+		return errors.New("invalid arguments")
+	}
+
+	matcher, err := filter.NewMatcher(positional[0], positional[1], positional[2])
+	if err != nil {
+		return err
+	}
+	if err := alert.ValidateAction(positional[3], positional[4]); err != nil {
+		return err
+	}
+
+	ruleDB, err := s.DB.CreateAlertRule(context.Background(), database.CreateAlertRuleParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feedID,
+		Field:     matcher.Field,
+		MatchType: matcher.MatchType,
+		Pattern:   matcher.Pattern,
+		Action:    positional[3],
+		Target:    positional[4],
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("alert rule %v added\n", shortID(ruleDB.ID))
+	if (ruleDB.Action == alert.ActionCommand || ruleDB.Action == alert.ActionFile) && user.Role != auth.RoleAdmin {
+		fmt.Println("command and file alerts only run when you run agg yourself")
+	}
+	return nil
+}
+
+func listAlertRules(s *state.State, user database.User, args []string) error {
+	if len(args) != 0 {
+		return errors.New("invalid arguments")
+	}
+
+	rules, err := s.DB.GetAlertRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		fmt.Println("no alert rules")
+		return nil
+	}
+
+	for _, rule := range rules {
+		line := fmt.Sprintf("%v %v %v %q -> %v %v", shortID(rule.ID), rule.Field, rule.MatchType, rule.Pattern, rule.Action, rule.Target)
+		if rule.FeedName.Valid {
+			line += " (feed: " + rule.FeedName.String + ")"
+		} else {
+			line += " (all feeds)"
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func deleteAlertRule(s *state.State, user database.User, args []string) error {
+	if len(args) != 1 {
+		return errors.New("invalid arguments")
+	}
+
+	rules, err := s.DB.GetAlertRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+	id, err := findByIDPrefix(ids, args[0])
+	if err != nil {
+		return fmt.Errorf("alert rule: %w", err)
+	}
+
+	if err := s.DB.DeleteAlertRule(context.Background(), database.DeleteAlertRuleParams{
+		ID:     id,
+		UserID: user.ID,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("alert rule %v deleted\n", shortID(id))
+	return nil
+}
+
+// alertRule pairs a stored alert rule with its compiled matcher
+type alertRule struct {
+	database.AlertRule
+	matcher filter.Matcher
+}
+
+// loadAlertRules returns the alert rules of every user following feedID
+// that apply to that feed. Command and file rules run on this machine, so
+// only the runner's own and admins' are included.
+func loadAlertRules(s *state.State, feedID uuid.UUID, runner database.User) ([]alertRule, error) {
+	rulesDB, err := s.DB.GetAlertRulesForFeed(context.Background(), database.GetAlertRulesForFeedParams{
+		FeedID:   uuid.NullUUID{UUID: feedID, Valid: true},
+		RunnerID: runner.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]alertRule, 0, len(rulesDB))
+	for _, ruleDB := range rulesDB {
+		matcher, err := filter.NewMatcher(ruleDB.Field, ruleDB.MatchType, ruleDB.Pattern)
+		if err != nil {
+			fmt.Printf("skipping alert rule %v: %v\n", shortID(ruleDB.ID), err)
+			continue
+		}
+		rules = append(rules, alertRule{AlertRule: ruleDB, matcher: matcher})
+	}
+	return rules, nil
+}
+
+// maxRunningAlerts bounds how many alert actions run at once
+const maxRunningAlerts = 4
+
+var alertSlots = make(chan struct{}, maxRunningAlerts)
+
+// fireAlerts starts the action of every rule matching a newly added post in
+// the background, so a slow action doesn't hold up fetching. Failed actions
+// are reported but don't stop the aggregator.
+func fireAlerts(rules []alertRule, feed database.Feed, post database.Post) {
+	if len(rules) == 0 {
+		return
+	}
+
+	filterable := filterPost(post)
+	for _, rule := range rules {
+		if !rule.matcher.Match(filterable) {
+			continue
+		}
+
+		event := alert.Event{
+			RuleID:  rule.ID.String(),
+			Pattern: rule.Pattern,
+			FiredAt: time.Now().UTC(),
+			Feed:    alert.Feed{Name: feed.Name, URL: feed.Url},
+			Post: alert.Post{
+				Title:       post.Title.String,
+				URL:         post.Url,
+				Description: filterable.Description,
+				Author:      post.Author.String,
+				Categories:  post.Categories,
+			},
+		}
+		if post.PublishedAt.Valid {
+			event.Post.PublishedAt = &post.PublishedAt.Time
+		}
+
+		go func(rule alertRule, event alert.Event) {
+			alertSlots <- struct{}{}
+			defer func() { <-alertSlots }()
+
+			if err := alert.Fire(context.Background(), rule.Action, rule.Target, event); err != nil {
+				fmt.Printf("alert %v failed: %v\n", shortID(rule.ID), err)
+				return
+			}
+			fmt.Printf("alert %v fired for %v\n", shortID(rule.ID), event.Post.Title)
+		}(rule, event)
+	}
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/api"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/google/uuid"
+)
+
+// HandlerAPIKey dispatches the API key subcommands:
+//
+//	apikey create [name]
+//	apikey list
+//	apikey delete <key_id>
+func HandlerAPIKey(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) == 0 {
+		return errors.New("invalid arguments")
+	}
+
+	args := cmd.arguments[1:]
+	switch cmd.arguments[0] {
+	case "create":
+		return createAPIKey(s, user, args)
+	case "list":
+		return listAPIKeys(s, user, args)
+	case "delete":
+		return deleteAPIKey(s, user, args)
+	}
+	return fmt.Errorf("unknown apikey subcommand: %v", cmd.arguments[0])
+}
+
+func createAPIKey(s *state.State, user database.User, args []string) error {
+	name := strings.Join(args, " ")
+	if name == "" {
+		name = "api key"
+	}
+
+	key, hash, err := api.NewKey()
+	if err != nil {
+		return err
+	}
+
+	keyDB, err := s.DB.CreateAPIKey(context.Background(), database.CreateAPIKeyParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      name,
+		KeyHash:   hash,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("API key %v created, it won't be shown again:\n%v\n", shortID(keyDB.ID), key)
+	return nil
+}
+
+func listAPIKeys(s *state.State, user database.User, args []string) error {
+	if len(args) != 0 {
+		return errors.New("invalid arguments")
+	}
+
+	keys, err := s.DB.GetAPIKeysForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		fmt.Println("no API keys")
+		return nil
+	}
+
+	for _, key := range keys {
+		lastUsed := "never used"
+		if key.LastUsedAt.Valid {
+			lastUsed = "last used " + key.LastUsedAt.Time.Format(time.RFC1123)
+		}
+		fmt.Printf("%v %v (created %v, %v)\n", shortID(key.ID), key.Name, key.CreatedAt.Format(time.RFC1123), lastUsed)
+	}
+	return nil
+}
+
+func deleteAPIKey(s *state.State, user database.User, args []string) error {
+	if len(args) != 1 {
+		return errors.New("invalid arguments")
+	}
+
+	keys, err := s.DB.GetAPIKeysForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, key.ID)
+	}
+	id, err := findByIDPrefix(ids, args[0])
+	if err != nil {
+		return fmt.Errorf("API key: %w", err)
+	}
+
+	if err := s.DB.DeleteAPIKey(context.Background(), database.DeleteAPIKeyParams{
+		ID:     id,
+		UserID: user.ID,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("API key %v deleted\n", shortID(id))
+	return nil
+}
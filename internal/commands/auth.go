@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/auth"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/google/uuid"
+	"golang.org/x/term"
+)
+
+var errNotLoggedIn = errors.New("not logged in, run `gator login <username>`")
+
+// stdin is shared so several prompts can read lines from piped input
+var stdin = bufio.NewReader(os.Stdin)
+
+// readPassword prompts for a password without echoing it. Without a
+// terminal a line is read from stdin instead, so scripts can pipe it in.
+func readPassword(prompt string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		line, err := stdin.ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.New("could not read password")
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
+
+// readNewPassword prompts for a new password, asking twice on a terminal,
+// and returns its hash
+func readNewPassword(prompt string) (string, error) {
+	password, err := readPassword(prompt)
+	if err != nil {
+		return "", err
+	}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		again, err := readPassword("repeat password: ")
+		if err != nil {
+			return "", err
+		}
+		if again != password {
+			return "", errors.New("passwords don't match")
+		}
+	}
+	return auth.HashPassword(password)
+}
+
+// startSession creates a session for the user and saves its token in the
+// config
+func startSession(s *state.State, user database.User) error {
+	token, hash, err := auth.NewSessionToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.CreateSession(context.Background(), database.CreateSessionParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(auth.SessionLifetime),
+		UserID:    user.ID,
+		TokenHash: hash,
+	})
+	if err != nil {
+		return err
+	}
+	return s.Cfg.SetSession(user.Name, token)
+}
+
+// sessionUser returns the user the config's session token belongs to
+func sessionUser(s *state.State) (database.User, error) {
+	if s.Cfg.SessionToken == "" {
+		return database.User{}, errNotLoggedIn
+	}
+
+	session, err := s.DB.GetSessionByHash(context.Background(), database.GetSessionByHashParams{
+		TokenHash: auth.HashToken(s.Cfg.SessionToken),
+		ExpiresAt: time.Now(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, errors.New("session expired, run `gator login <username>` again")
+	}
+	if err != nil {
+		return database.User{}, err
+	}
+	return s.DB.GetUserByID(context.Background(), session.UserID)
+}
+
+// HandlerPasswd changes the current user's password and logs out every
+// other session
+func HandlerPasswd(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) != 0 {
+		return errors.New("invalid arguments")
+	}
+
+	current, err := readPassword("current password: ")
+	if err != nil {
+		return err
+	}
+	if err := auth.CheckPassword(user.PasswordHash.String, current); err != nil {
+		return errors.New("wrong password")
+	}
+
+	hash, err := readNewPassword("new password: ")
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.SetUserPassword(context.Background(), database.SetUserPasswordParams{
+		ID:           user.ID,
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+		UpdatedAt:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.DB.DeleteSessionsForUser(context.Background(), user.ID); err != nil {
+		return err
+	}
+	if err := startSession(s, user); err != nil {
+		return err
+	}
+
+	fmt.Println("password changed, other sessions have been logged out")
+	return nil
+}
+
+// HandlerLogout ends the current session, even if it has already expired
+func HandlerLogout(s *state.State, cmd Command) error {
+	if len(cmd.arguments) != 0 {
+		return errors.New("invalid arguments")
+	}
+	if s.Cfg.SessionToken == "" && s.Cfg.CurrentUsername == "" {
+		return errors.New("not logged in")
+	}
+
+	if s.Cfg.SessionToken != "" {
+		if err := s.DB.DeleteSessionByHash(context.Background(), auth.HashToken(s.Cfg.SessionToken)); err != nil {
+			return err
+		}
+	}
+
+	username := s.Cfg.CurrentUsername
+	if err := s.Cfg.SetSession("", ""); err != nil {
+		return err
+	}
+	fmt.Printf("user %v logged out\n", username)
+	return nil
+}
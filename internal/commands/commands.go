@@ -5,17 +5,29 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/acehotel33/bootdev-gator/internal/auth"
 	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/filter"
+	"github.com/acehotel33/bootdev-gator/internal/htmltext"
 	"github.com/acehotel33/bootdev-gator/internal/rss"
 	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/acehotel33/bootdev-gator/internal/tui"
 	"github.com/google/uuid"
+	"golang.org/x/term"
 )
 
+const browseWidth = 80
+
+// maxBrowseFetch caps how many posts browse reads while skipping filtered ones
+const maxBrowseFetch = 1000
+
 type Command struct {
 	name      string
 	arguments []string
@@ -30,16 +42,37 @@ func InitializeCommands() (*Commands, error) {
 		commandsMap: map[string]func(*state.State, Command) error{},
 	}
 	cmds.Register("login", HandlerLogin)
+	cmds.Register("logout", HandlerLogout)
 	cmds.Register("register", HandlerRegister)
-	cmds.Register("reset", HandlerReset)
+	cmds.Register("reset", middlewareAdmin(HandlerReset))
 	cmds.Register("users", HandlerGetAllUsers)
+	cmds.Register("user", middlewareLoggedIn(HandlerUser))
+	cmds.Register("passwd", middlewareLoggedIn(HandlerPasswd))
 	cmds.Register("agg", middlewareLoggedIn(HandlerAggregator))
 	cmds.Register("addfeed", middlewareLoggedIn(HandlerAddFeed))
 	cmds.Register("feeds", HandlerFeeds)
+	cmds.Register("serve", HandlerServe)
+	cmds.Register("feed", middlewareLoggedIn(HandlerFeed))
 	cmds.Register("follow", middlewareLoggedIn(HandlerFollow))
 	cmds.Register("following", middlewareLoggedIn(HandlerFollowing))
 	cmds.Register("unfollow", middlewareLoggedIn(HandlerUnfollow))
+	cmds.Register("folder", middlewareLoggedIn(HandlerFolder))
+	cmds.Register("filter", middlewareLoggedIn(HandlerFilter))
+	cmds.Register("alert", middlewareLoggedIn(HandlerAlert))
+	cmds.Register("webhook", middlewareLoggedIn(HandlerWebhook))
+	cmds.Register("digest", middlewareLoggedIn(HandlerDigest))
+	cmds.Register("timeline", middlewareLoggedIn(HandlerTimeline))
+	cmds.Register("apikey", middlewareLoggedIn(HandlerAPIKey))
+	cmds.Register("fever", middlewareLoggedIn(HandlerFever))
 	cmds.Register("browse", middlewareLoggedIn(HandlerBrowse))
+	cmds.Register("reader", middlewareLoggedIn(HandlerReader))
+	cmds.Register("download", middlewareLoggedIn(HandlerDownload))
+	cmds.Register("keepepisodes", middlewareLoggedIn(HandlerKeepEpisodes))
+	cmds.Register("import", middlewareLoggedIn(HandlerImport))
+	cmds.Register("export", middlewareLoggedIn(HandlerExport))
+	cmds.Register("gc", middlewareAdmin(HandlerGC))
+	cmds.Register("prune", middlewareAdmin(HandlerPrune))
+	cmds.Register("retention", middlewareAdmin(HandlerRetention))
 	return cmds, nil
 }
 
@@ -83,11 +116,36 @@ func HandlerRegister(s *state.State, cmd Command) error {
 	}
 
 	username := cmd.arguments[0]
+	_, err := s.DB.GetUser(context.Background(), username)
+	if err == nil {
+		return fmt.Errorf("user %v already exists", username)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	hash, err := readNewPassword("password: ")
+	if err != nil {
+		return err
+	}
+
+	// The first user to register runs the place
+	userCount, err := s.DB.CountUsers(context.Background())
+	if err != nil {
+		return err
+	}
+	role := auth.RoleMember
+	if userCount == 0 {
+		role = auth.RoleAdmin
+	}
+
 	createUserParams := database.CreateUserParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Name:      username,
+		ID:           uuid.New(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		Name:         username,
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+		Role:         role,
 	}
 
 	user, err := s.DB.CreateUser(context.Background(), createUserParams)
@@ -95,9 +153,10 @@ func HandlerRegister(s *state.State, cmd Command) error {
 		return errors.New("could not register user")
 	}
 
-	s.Cfg.SetUser(username)
-	fmt.Printf("user %v created\n", username)
-	fmt.Println(user)
+	if err := startSession(s, user); err != nil {
+		return err
+	}
+	fmt.Printf("user %v created as %v\n", username, role)
 
 	return nil
 }
@@ -108,27 +167,51 @@ func HandlerLogin(s *state.State, cmd Command) error {
 	}
 
 	username := cmd.arguments[0]
-	_, err := s.DB.GetUser(context.Background(), username)
-	if err != nil {
-		return errors.New("could not log in")
-	}
-	if err := s.Cfg.SetUser(username); err != nil {
+	user, err := s.DB.GetUser(context.Background(), username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return err
 	}
-	fmt.Printf("user has been set to: %v\n", username)
-	return nil
-}
 
-func HandlerReset(s *state.State, cmd Command) error {
-	if len(cmd.arguments) != 0 {
-		return errors.New("invalid arguments")
+	// Users registered before passwords existed have to be given one by an
+	// admin. The only exception is an admin logging in before anyone has a
+	// password, since nobody could set theirs otherwise.
+	if err == nil && !user.PasswordHash.Valid {
+		withPassword, err := s.DB.CountUsersWithPassword(context.Background())
+		if err != nil {
+			return err
+		}
+		if user.Role != auth.RoleAdmin || withPassword > 0 {
+			return fmt.Errorf("user %v has no password, ask an admin to run 'user passwd %v'", username, username)
+		}
+
+		fmt.Printf("user %v has no password yet, choose one\n", username)
+		hash, err := readNewPassword("new password: ")
+		if err != nil {
+			return err
+		}
+		err = s.DB.SetUserPassword(context.Background(), database.SetUserPasswordParams{
+			ID:           user.ID,
+			PasswordHash: sql.NullString{String: hash, Valid: true},
+			UpdatedAt:    time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		password, err := readPassword("password: ")
+		if err != nil {
+			return err
+		}
+		// Unknown users and wrong passwords give the same error
+		if !user.PasswordHash.Valid || auth.CheckPassword(user.PasswordHash.String, password) != nil {
+			return auth.ErrWrongPassword
+		}
 	}
 
-	if err := s.DB.ResetUsers(context.Background()); err != nil {
+	if err := startSession(s, user); err != nil {
 		return err
 	}
-
-	fmt.Println("users reset")
+	fmt.Printf("user has been set to: %v\n", username)
 	return nil
 }
 
@@ -138,9 +221,14 @@ func HandlerGetAllUsers(s *state.State, cmd Command) error {
 		return errors.New("could not get users")
 	}
 
+	// Not being logged in just means nobody is marked current
+	current, _ := sessionUser(s)
 	for _, user := range users {
 		line := "* " + user.Name
-		if user.Name == s.Cfg.CurrentUsername {
+		if user.Role == auth.RoleAdmin {
+			line = line + " (admin)"
+		}
+		if user.ID == current.ID {
 			line = line + " (current)"
 		}
 		fmt.Println(line)
@@ -173,26 +261,74 @@ func HandlerAggregator(s *state.State, cmd Command, user database.User) error {
 
 	// fmt.Println("Ticker created")
 
+	var lastPrune time.Time
 	for ; ; <-ticker.C {
 		scrapeFeeds(s, user)
+
+		if err := deliverWebhooks(s); err != nil {
+			fmt.Println(err)
+		}
+
+		if time.Since(lastPrune) >= pruneInterval {
+			if _, err := prunePosts(s); err != nil {
+				fmt.Println(err)
+			}
+			lastPrune = time.Now()
+		}
 	}
 
 }
 
 func HandlerAddFeed(s *state.State, cmd Command, user database.User) error {
-	if len(cmd.arguments) != 2 {
+	if len(cmd.arguments) == 0 || len(cmd.arguments) > 2 {
 		return errors.New("invalid arguments")
 	}
-	feedName := cmd.arguments[0]
-	feedURL := cmd.arguments[1]
 
-	currentUserID := user.ID
+	// addfeed [feed_name] <feed_url>: without a name the feed's own title is used
+	feedName := ""
+	feedURL := cmd.arguments[0]
+	if len(cmd.arguments) == 2 {
+		feedName = cmd.arguments[0]
+		feedURL = cmd.arguments[1]
+	}
 
-	_, err := rss.FetchFeed(context.Background(), feedURL)
+	dbFeed, err := addFeed(s, user, feedName, feedURL, chooseFeedLink)
 	if err != nil {
 		return err
 	}
 
+	fmt.Println(dbFeed)
+	return nil
+}
+
+// addFeed creates a feed from feedURL, or from a feed the page at feedURL
+// advertises, and follows it for the user
+func addFeed(s *state.State, user database.User, feedName, feedURL string, choose func([]rss.FeedLink) (rss.FeedLink, error)) (database.Feed, error) {
+	currentUserID := user.ID
+
+	fetchedURL, rssFeed, err := fetchOrDiscoverFeed(feedURL, choose)
+	if err != nil {
+		return database.Feed{}, err
+	}
+	if fetchedURL != feedURL {
+		fmt.Printf("using feed %v\n", fetchedURL)
+		feedURL = fetchedURL
+	}
+	siteURL := rssFeed.Channel.Link
+	description := strings.TrimSpace(rssFeed.Channel.Description)
+	imageURL := rssFeed.ImageURL()
+
+	if feedName == "" {
+		feedName = strings.TrimSpace(rssFeed.Channel.Title)
+	}
+	if feedName == "" {
+		parsedURL, err := url.Parse(feedURL)
+		if err != nil {
+			return database.Feed{}, err
+		}
+		feedName = parsedURL.Host
+	}
+
 	createFeedParams := database.CreateFeedParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
@@ -203,11 +339,14 @@ func HandlerAddFeed(s *state.State, cmd Command, user database.User) error {
 			UUID:  currentUserID,
 			Valid: true,
 		},
+		SiteUrl:     sql.NullString{String: siteURL, Valid: siteURL != ""},
+		Description: sql.NullString{String: description, Valid: description != ""},
+		ImageUrl:    sql.NullString{String: imageURL, Valid: imageURL != ""},
 	}
 
-	dbFeed, err := s.DB.CreateFeed(context.Background(), createFeedParams)
+	dbFeed, err := createFeedWithUniqueName(s, createFeedParams)
 	if err != nil {
-		return err
+		return database.Feed{}, err
 	}
 
 	createFeedFollowParams := database.CreateFeedFollowParams{
@@ -225,11 +364,23 @@ func HandlerAddFeed(s *state.State, cmd Command, user database.User) error {
 	}
 	_, err = s.DB.CreateFeedFollow(context.Background(), createFeedFollowParams)
 	if err != nil {
-		return err
+		return database.Feed{}, err
 	}
 
-	fmt.Println(dbFeed)
-	return nil
+	return dbFeed, nil
+}
+
+// createFeedWithUniqueName inserts a feed, adding a numeric suffix to its
+// name while another feed already uses it
+func createFeedWithUniqueName(s *state.State, params database.CreateFeedParams) (database.Feed, error) {
+	baseName := params.Name
+	for i := 2; ; i++ {
+		feed, err := s.DB.CreateFeed(context.Background(), params)
+		if err == nil || !strings.Contains(err.Error(), "unique constraint \"feeds_name_key\"") || i > 100 {
+			return feed, err
+		}
+		params.Name = fmt.Sprintf("%v (%d)", baseName, i)
+	}
 }
 
 func HandlerFeeds(s *state.State, cmd Command) error {
@@ -327,8 +478,27 @@ func HandlerFollowing(s *state.State, cmd Command, user database.User) error {
 		return err
 	}
 
+	// Feeds without a folder are listed first, then each folder in turn
+	folders := []string{}
+	byFolder := map[string][]string{}
 	for i := range following {
-		fmt.Println(following[i].FeedName)
+		if !following[i].Folder.Valid {
+			fmt.Println(following[i].FeedName)
+			continue
+		}
+		folder := following[i].Folder.String
+		if _, ok := byFolder[folder]; !ok {
+			folders = append(folders, folder)
+		}
+		byFolder[folder] = append(byFolder[folder], following[i].FeedName)
+	}
+
+	sort.Strings(folders)
+	for _, folder := range folders {
+		fmt.Printf("[%v]\n", folder)
+		for _, feedName := range byFolder[folder] {
+			fmt.Printf("  %v\n", feedName)
+		}
 	}
 
 	return nil
@@ -388,18 +558,65 @@ func scrapeFeeds(s *state.State, user database.User) error {
 	fmt.Printf("Fetching feed: %v - %v\n", markedFeed.Name, markedFeed.Url)
 	fmt.Println("----------")
 
+	ingestRules, err := loadIngestRules(s)
+	if err != nil {
+		return err
+	}
+
+	alertRules, err := loadAlertRules(s, markedFeed.ID, user)
+	if err != nil {
+		return err
+	}
+
+	hooks, err := s.DB.GetWebhooksForFeed(context.Background(), uuid.NullUUID{UUID: markedFeed.ID, Valid: true})
+	if err != nil {
+		return err
+	}
+
 	fetchedItems := fetchedFeed.Channel.Item
 	for _, item := range fetchedItems {
+		if shown, _ := filter.Evaluate(ingestRules, filterItem(markedFeed.ID, item)); !shown {
+			continue
+		}
+
+		// Posts removed by retention stay removed
+		pruned, err := s.DB.IsPostPruned(context.Background(), item.Link)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		if pruned {
+			continue
+		}
+
+		duration, hasDuration := item.Duration()
+		episode, hasEpisode := item.Episode()
+		season, hasSeason := item.Season()
+		publishedAt, hasPublishedAt := rss.ParseDate(item.PubDate)
+
+		// A nil slice is sent as NULL, which the NOT NULL column rejects
+		categories := item.Categories
+		if categories == nil {
+			categories = []string{}
+		}
 
 		postDB, err := s.DB.CreatePost(context.Background(), database.CreatePostParams{
-			ID:          uuid.New(),
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-			Title:       sql.NullString{String: item.Title, Valid: true},
-			Url:         item.Link,
-			Description: sql.NullString{String: item.Description, Valid: true},
-			PublishedAt: sql.NullTime{Time: timeStringParser(item.PubDate), Valid: true},
-			FeedID:      uuid.NullUUID{UUID: markedFeed.ID, Valid: true},
+			ID:              uuid.New(),
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+			Title:           sql.NullString{String: item.Title, Valid: true},
+			Url:             item.Link,
+			Description:     sql.NullString{String: item.Description, Valid: true},
+			PublishedAt:     sql.NullTime{Time: publishedAt, Valid: hasPublishedAt},
+			FeedID:          uuid.NullUUID{UUID: markedFeed.ID, Valid: true},
+			Content:         sql.NullString{String: item.Content, Valid: item.Content != ""},
+			Author:          sql.NullString{String: item.AuthorName(), Valid: item.AuthorName() != ""},
+			Categories:      categories,
+			CommentsUrl:     sql.NullString{String: item.Comments, Valid: item.Comments != ""},
+			DurationSeconds: sql.NullInt32{Int32: int32(duration.Seconds()), Valid: hasDuration},
+			Episode:         sql.NullInt32{Int32: episode, Valid: hasEpisode},
+			Season:          sql.NullInt32{Int32: season, Valid: hasSeason},
+			ImageUrl:        sql.NullString{String: item.ITunesImage.Href, Valid: item.ITunesImage.Href != ""},
 		})
 		if err != nil {
 			if strings.Contains(err.Error(), "unique constraint \"posts_url_key\"") {
@@ -410,6 +627,11 @@ func scrapeFeeds(s *state.State, user database.User) error {
 			}
 		} else {
 			fmt.Printf("%v added to posts DB\n", postDB.Title)
+			if err := saveEnclosures(s, postDB, item.Enclosures); err != nil {
+				fmt.Println(err)
+			}
+			fireAlerts(alertRules, markedFeed, postDB)
+			enqueueWebhooks(s, hooks, markedFeed, postDB)
 		}
 
 	}
@@ -417,52 +639,161 @@ func scrapeFeeds(s *state.State, user database.User) error {
 	return nil
 }
 
-func timeStringParser(s string) time.Time {
-
-	formats := []string{
-		time.RFC1123,
-		time.RFC822,
-		"2006-01-02T15:04:05-07:00"}
-
-	var parsedTime time.Time
-	var err error
-	for _, format := range formats {
-		parsedTime, err = time.Parse(format, s)
-		if err == nil {
-			break
+// saveEnclosures stores a post's enclosures, carrying on past the ones that
+// fail and returning their errors together
+func saveEnclosures(s *state.State, post database.Post, enclosures []rss.RSSEnclosure) error {
+	var errs []error
+	for _, enclosure := range enclosures {
+		if enclosure.URL == "" {
+			continue
+		}
+		length, ok := enclosure.Size()
+
+		_, err := s.DB.CreatePostEnclosure(context.Background(), database.CreatePostEnclosureParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			PostID:    post.ID,
+			Url:       enclosure.URL,
+			Type:      sql.NullString{String: enclosure.Type, Valid: enclosure.Type != ""},
+			Length:    sql.NullInt64{Int64: length, Valid: ok},
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not save enclosure %v: %w", enclosure.URL, err))
 		}
 	}
-	if err != nil {
-		parsedTime = time.Time{}
-	}
-
-	return parsedTime
+	return errors.Join(errs...)
 }
 
+// HandlerBrowse prints the latest posts from followed feeds. A bare
+// number is the limit unless one of the user's folders has that name.
+//
+//	browse [--limit n] [limit] [folder]
 func HandlerBrowse(s *state.State, cmd Command, user database.User) error {
-	if len(cmd.arguments) > 1 {
-		return errors.New("invalid arguments")
+	folders, err := userFolders(s, user)
+	if err != nil {
+		return err
 	}
 
 	var limit int32
 	limit = 2
-	if len(cmd.arguments) == 1 {
-		limitInt, err := strconv.Atoi(cmd.arguments[0])
-		limit = int32(limitInt)
-		if err != nil {
-			limit = 2
+	hasLimit := false
+	folder := ""
+	for i := 0; i < len(cmd.arguments); i++ {
+		arg := cmd.arguments[i]
+		if arg == "--limit" {
+			if i+1 >= len(cmd.arguments) {
+				return errors.New("--limit needs a value")
+			}
+			limitInt, err := strconv.Atoi(cmd.arguments[i+1])
+			if err != nil || limitInt < 1 {
+				return fmt.Errorf("invalid limit: %v", cmd.arguments[i+1])
+			}
+			limit, hasLimit = int32(limitInt), true
+			i++
+			continue
 		}
 
+		limitInt, err := strconv.Atoi(arg)
+		switch {
+		case folder == "" && (folders[arg] || err != nil):
+			folder = arg
+		case !hasLimit && err == nil && limitInt > 0:
+			limit, hasLimit = int32(limitInt), true
+		default:
+			return errors.New("invalid arguments")
+		}
+	}
+
+	fetchPosts := func(limit int32) ([]database.Post, error) {
+		if folder == "" {
+			return s.DB.GetPostsForUser(context.Background(), database.GetPostsForUserParams{UserID: uuid.NullUUID{UUID: user.ID, Valid: true}, Limit: limit})
+		}
+		return s.DB.GetPostsForUserInFolder(context.Background(), database.GetPostsForUserInFolderParams{UserID: uuid.NullUUID{UUID: user.ID, Valid: true}, Limit: limit, Folder: folder})
 	}
-	postsDB, err := s.DB.GetPostsForUser(context.Background(), database.GetPostsForUserParams{UserID: uuid.NullUUID{UUID: user.ID, Valid: true}, Limit: limit})
+
+	rules, err := loadFilterRules(s, user.ID)
 	if err != nil {
 		return err
 	}
 
+	// Filtered posts are hidden after fetching, so keep fetching more until
+	// enough posts are visible or there are no more to fetch
+	var postsDB []database.Post
+	for fetchLimit := limit; ; fetchLimit *= 2 {
+		fetched, err := fetchPosts(fetchLimit)
+		if err != nil {
+			return err
+		}
+
+		postsDB = postsDB[:0]
+		for _, post := range fetched {
+			if shown, _ := filter.Evaluate(rules, filterPost(post)); shown {
+				postsDB = append(postsDB, post)
+			}
+		}
+		if len(postsDB) >= int(limit) || len(fetched) < int(fetchLimit) || fetchLimit >= maxBrowseFetch {
+			break
+		}
+	}
+	if len(postsDB) > int(limit) {
+		postsDB = postsDB[:limit]
+	}
+
+	renderOpts := htmltext.Options{
+		Width: browseWidth,
+		ANSI:  term.IsTerminal(int(os.Stdout.Fd())),
+	}
+
 	for _, post := range postsDB {
 		fmt.Println("---------")
 		fmt.Println(post.Title.String)
-		fmt.Println(post.Description.String)
+		if post.Author.Valid {
+			fmt.Printf("by %v\n", post.Author.String)
+		}
+		if len(post.Categories) > 0 {
+			fmt.Printf("categories: %v\n", strings.Join(post.Categories, ", "))
+		}
+		fmt.Println()
+
+		body := post.Description.String
+		if post.Content.Valid {
+			body = post.Content.String
+		}
+		fmt.Println(htmltext.Render(body, renderOpts))
+
+		if post.CommentsUrl.Valid {
+			fmt.Printf("\ncomments: %v\n", post.CommentsUrl.String)
+		}
+
+		enclosures, err := s.DB.GetEnclosuresForPost(context.Background(), post.ID)
+		if err != nil {
+			return err
+		}
+		if post.Episode.Valid || post.DurationSeconds.Valid {
+			fmt.Println()
+			if post.Season.Valid {
+				fmt.Printf("season %d ", post.Season.Int32)
+			}
+			if post.Episode.Valid {
+				fmt.Printf("episode %d ", post.Episode.Int32)
+			}
+			if post.DurationSeconds.Valid {
+				fmt.Printf("(%v)", time.Duration(post.DurationSeconds.Int32)*time.Second)
+			}
+			fmt.Println()
+		}
+		for _, enclosure := range enclosures {
+			fmt.Printf("enclosure: %v", enclosure.Url)
+			if enclosure.Type.Valid {
+				fmt.Printf(" (%v", enclosure.Type.String)
+				if enclosure.Length.Valid {
+					fmt.Printf(", %d bytes", enclosure.Length.Int64)
+				}
+				fmt.Print(")")
+			}
+			fmt.Println()
+		}
 		fmt.Println("---------")
 		fmt.Println()
 	}
@@ -470,9 +801,50 @@ func HandlerBrowse(s *state.State, cmd Command, user database.User) error {
 	return nil
 }
 
+// userFolders returns the names of the user's folders along with the
+// parents of nested ones
+func userFolders(s *state.State, user database.User) (map[string]bool, error) {
+	following, err := s.DB.GetFeedFollowsForUser(context.Background(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	folders := map[string]bool{}
+	for _, follow := range following {
+		if !follow.Folder.Valid {
+			continue
+		}
+		parts := strings.Split(follow.Folder.String, "/")
+		for i := range parts {
+			folders[strings.Join(parts[:i+1], "/")] = true
+		}
+	}
+	return folders, nil
+}
+
+func HandlerReader(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) > 1 {
+		return errors.New("invalid arguments")
+	}
+
+	refresh := time.Minute
+	if len(cmd.arguments) == 1 {
+		timeDuration, err := time.ParseDuration(cmd.arguments[0])
+		if err != nil {
+			return err
+		}
+		if timeDuration <= 0 {
+			return errors.New("refresh interval must be positive")
+		}
+		refresh = timeDuration
+	}
+
+	return tui.New(s.DB, user, refresh).Run(context.Background())
+}
+
 func middlewareLoggedIn(handler func(s *state.State, cmd Command, user database.User) error) func(*state.State, Command) error {
 	return func(s *state.State, cmd Command) error {
-		user, err := s.DB.GetUser(context.Background(), s.Cfg.CurrentUsername)
+		user, err := sessionUser(s)
 		if err != nil {
 			return err
 		}
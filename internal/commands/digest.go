@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/digest"
+	"github.com/acehotel33/bootdev-gator/internal/htmltext"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultDigestWindow is how far back the first digest looks
+	defaultDigestWindow = 7 * 24 * time.Hour
+	maxDigestPosts      = 500
+	digestSummaryLength = 300
+	defaultDigestFrom   = "gator@localhost"
+)
+
+// HandlerDigest emails the user's unread posts since their last digest.
+//
+//	digest [--to <email>] [--dry-run]
+//
+// --to saves the address for later digests, --dry-run prints the plain-text
+// version without sending it or moving the watermark.
+func HandlerDigest(s *state.State, cmd Command, user database.User) error {
+	dryRun := false
+	for i := 0; i < len(cmd.arguments); i++ {
+		switch cmd.arguments[i] {
+		case "--dry-run":
+			dryRun = true
+		case "--to":
+			if i+1 >= len(cmd.arguments) {
+				return errors.New("--to needs an email address")
+			}
+			i++
+			address, err := mail.ParseAddress(cmd.arguments[i])
+			if err != nil {
+				return fmt.Errorf("invalid email address: %w", err)
+			}
+			user, err = s.DB.SetUserEmail(context.Background(), database.SetUserEmailParams{
+				ID:        user.ID,
+				Email:     sql.NullString{String: address.Address, Valid: true},
+				UpdatedAt: time.Now(),
+			})
+			if err != nil {
+				return err
+			}
+		default:
+			return errors.New("invalid arguments")
+		}
+	}
+
+	until := time.Now()
+	since := until.Add(-defaultDigestWindow)
+	if user.LastDigestAt.Valid {
+		since = user.LastDigestAt.Time
+	}
+
+	d, watermark, err := buildDigest(s, user.ID, since, until)
+	if err != nil {
+		return err
+	}
+	if d.PostCount() == 0 {
+		fmt.Printf("no unread posts since %v\n", since.Format(time.RFC1123))
+		return nil
+	}
+
+	if dryRun {
+		fmt.Print(d.Text())
+		return nil
+	}
+
+	if !user.Email.Valid {
+		return errors.New("no email address set, run digest --to <email> first")
+	}
+	smtpCfg := s.Cfg.SMTP
+	if smtpCfg.Host == "" {
+		return errors.New("no SMTP server configured, set smtp.host in the config file")
+	}
+
+	from := smtpCfg.From
+	if from == "" {
+		from = defaultDigestFrom
+	}
+	fromAddress, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("invalid smtp.from address: %w", err)
+	}
+
+	server := digest.Server{
+		Host:     smtpCfg.Host,
+		Port:     smtpCfg.Port,
+		Username: smtpCfg.Username,
+		Password: smtpCfg.Password,
+	}
+	err = digest.Deliver(server, fromAddress, user.Email.String, d, func() error {
+		return s.DB.SetUserLastDigestAt(context.Background(), database.SetUserLastDigestAtParams{
+			ID:           user.ID,
+			LastDigestAt: sql.NullTime{Time: watermark, Valid: true},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("digest with %d posts sent to %v\n", d.PostCount(), user.Email.String)
+	return nil
+}
+
+// buildDigest collects unread posts added between since and until, grouped
+// by feed in the order the query returns them. It also returns the time the
+// next digest should start from, which is before until when the digest was
+// capped at maxDigestPosts.
+func buildDigest(s *state.State, userID uuid.UUID, since, until time.Time) (digest.Digest, time.Time, error) {
+	postsDB, err := s.DB.GetUnreadPostsForDigest(context.Background(), database.GetUnreadPostsForDigestParams{
+		UserID:   uuid.NullUUID{UUID: userID, Valid: true},
+		Since:    since,
+		Until:    until,
+		RowLimit: maxDigestPosts,
+	})
+	if err != nil {
+		return digest.Digest{}, time.Time{}, err
+	}
+
+	d := digest.Digest{Since: since, Until: until}
+	watermark := until
+	if len(postsDB) == maxDigestPosts {
+		d.Truncated = true
+		watermark = since
+		for _, post := range postsDB {
+			if post.CreatedAt.After(watermark) {
+				watermark = post.CreatedAt
+			}
+		}
+	}
+
+	for _, post := range postsDB {
+		if len(d.Feeds) == 0 || d.Feeds[len(d.Feeds)-1].Name != post.FeedName {
+			d.Feeds = append(d.Feeds, digest.Feed{Name: post.FeedName})
+		}
+
+		body := post.Description.String
+		if post.Content.Valid {
+			body = post.Content.String
+		}
+		entry := digest.Post{
+			Title:   post.Title.String,
+			URL:     post.Url,
+			Summary: truncateText(htmltext.Render(body, htmltext.Options{}), digestSummaryLength),
+		}
+		if post.PublishedAt.Valid {
+			entry.PublishedAt = post.PublishedAt.Time
+		}
+
+		feed := &d.Feeds[len(d.Feeds)-1]
+		feed.Posts = append(feed.Posts, entry)
+	}
+	return d, watermark, nil
+}
+
+// truncateText shortens s to at most n runes, cutting at a word boundary
+func truncateText(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	cut := string(runes[:n])
+	if space := strings.LastIndex(cut, " "); space > 0 {
+		cut = cut[:space]
+	}
+	return cut + "…"
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/acehotel33/bootdev-gator/internal/rss"
+	"golang.org/x/term"
+)
+
+// fetchOrDiscoverFeed fetches feedURL as a feed, and when it isn't one
+// looks for feeds advertised by the page, using choose to pick when there
+// is more than one. It returns the URL that was finally used.
+func fetchOrDiscoverFeed(feedURL string, choose func([]rss.FeedLink) (rss.FeedLink, error)) (string, *rss.RSSFeed, error) {
+	rssFeed, fetchErr := rss.FetchFeed(context.Background(), feedURL)
+	if fetchErr == nil {
+		return feedURL, rssFeed, nil
+	}
+
+	links, err := rss.Discover(context.Background(), feedURL)
+	if err != nil || len(links) == 0 {
+		return "", nil, fetchErr
+	}
+
+	link := links[0]
+	if len(links) > 1 {
+		link, err = choose(links)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	rssFeed, err = rss.FetchFeed(context.Background(), link.URL)
+	if err != nil {
+		return "", nil, err
+	}
+	return link.URL, rssFeed, nil
+}
+
+// firstFeedLink picks the first feed a page advertises, for callers that
+// can't ask
+func firstFeedLink(links []rss.FeedLink) (rss.FeedLink, error) {
+	return links[0], nil
+}
+
+func chooseFeedLink(links []rss.FeedLink) (rss.FeedLink, error) {
+	fmt.Println("found multiple feeds:")
+	for i, link := range links {
+		line := fmt.Sprintf("  %d) %v", i+1, link.URL)
+		if link.Title != "" {
+			line += " - " + link.Title
+		}
+		if link.Type != "" {
+			line += " (" + link.Type + ")"
+		}
+		fmt.Println(line)
+	}
+
+	// Without a terminal to prompt on, take the first advertised feed
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return links[0], nil
+	}
+
+	fmt.Printf("choose a feed [1-%d]: ", len(links))
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return rss.FeedLink{}, err
+	}
+	choice, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || choice < 1 || choice > len(links) {
+		return rss.FeedLink{}, errors.New("invalid choice")
+	}
+	return links[choice-1], nil
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/podcast"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/google/uuid"
+)
+
+// defaultKeepEpisodes applies to follows without their own keepepisodes policy
+const defaultKeepEpisodes = 5
+
+func HandlerDownload(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) > 1 {
+		return errors.New("invalid arguments")
+	}
+
+	var onlyFeed uuid.NullUUID
+	if len(cmd.arguments) == 1 {
+		feedDB, err := s.DB.GetFeedByUrl(context.Background(), cmd.arguments[0])
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("unknown feed %v", cmd.arguments[0])
+		}
+		if err != nil {
+			return err
+		}
+		onlyFeed = uuid.NullUUID{UUID: feedDB.ID, Valid: true}
+	}
+
+	downloadDir, err := s.Cfg.EpisodeDir()
+	if err != nil {
+		return err
+	}
+
+	following, err := s.DB.GetFeedFollowsForUser(context.Background(), uuid.NullUUID{
+		UUID:  user.ID,
+		Valid: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, follow := range following {
+		if onlyFeed.Valid && follow.FeedID != onlyFeed {
+			continue
+		}
+		found = true
+
+		keep := defaultKeepEpisodes
+		if follow.KeepEpisodes.Valid {
+			keep = int(follow.KeepEpisodes.Int32)
+		}
+
+		if err := downloadEpisodes(s, follow, downloadDir, keep); err != nil {
+			fmt.Printf("%v: %v\n", follow.FeedName, err)
+		}
+	}
+
+	if onlyFeed.Valid && !found {
+		return errors.New("you are not following this feed")
+	}
+	return nil
+}
+
+func downloadEpisodes(s *state.State, follow database.GetFeedFollowsForUserRow, downloadDir string, keep int) error {
+	episodes, err := s.DB.GetEpisodesForFeed(context.Background(), database.GetEpisodesForFeedParams{
+		FeedID: follow.FeedID,
+		Limit:  int32(keep),
+	})
+	if err != nil {
+		return err
+	}
+
+	if !follow.FeedID.Valid {
+		return errors.New("follow has no feed")
+	}
+	feedDir := filepath.Join(downloadDir, podcast.FeedDir(follow.FeedID.UUID.String()))
+	if len(episodes) > 0 {
+		if err := os.MkdirAll(feedDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	kept := map[string]bool{}
+	for _, episode := range episodes {
+		filename := podcast.Filename(episode.PostTitle.String, episode.PostPublishedAt.Time, episode.Url, episode.Type.String)
+		if kept[filename] {
+			continue
+		}
+		kept[filename] = true
+
+		dest := filepath.Join(feedDir, filename)
+		if _, err := os.Stat(dest); err == nil {
+			continue
+		}
+
+		fmt.Printf("downloading %v - %v\n", follow.FeedName, episode.PostTitle.String)
+		if err := podcast.Download(context.Background(), episode.Url, dest); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Printf("saved %v\n", dest)
+	}
+
+	removed, err := podcast.Prune(feedDir, kept)
+	for _, name := range removed {
+		fmt.Printf("removed old episode %v\n", name)
+	}
+	return err
+}
+
+func HandlerKeepEpisodes(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) != 2 {
+		return errors.New("invalid arguments")
+	}
+
+	feedURL := cmd.arguments[0]
+	keep, err := strconv.Atoi(cmd.arguments[1])
+	if err != nil || keep < 0 {
+		return errors.New("number of episodes must be a non-negative integer")
+	}
+
+	feedDB, err := s.DB.GetFeedByUrl(context.Background(), feedURL)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.SetFeedFollowKeepEpisodes(context.Background(), database.SetFeedFollowKeepEpisodesParams{
+		UserID:       uuid.NullUUID{UUID: user.ID, Valid: true},
+		FeedID:       uuid.NullUUID{UUID: feedDB.ID, Valid: true},
+		KeepEpisodes: sql.NullInt32{Int32: int32(keep), Valid: true},
+		UpdatedAt:    time.Now(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("you are not following this feed")
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("keeping the last %d episodes of %v\n", keep, feedDB.Name)
+	return nil
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/auth"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/rss"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/google/uuid"
+)
+
+// HandlerFeed dispatches the feed management subcommands:
+//
+//	feed rename <feed_url> <new_name>
+//	feed set-url <feed_url> <new_url>
+//	feed delete <feed_url> [--yes]
+//	feed retention <feed_url> <max_age_hours|-> <max_count|->
+func HandlerFeed(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) < 2 {
+		return errors.New("invalid arguments")
+	}
+
+	subcommand := cmd.arguments[0]
+	args := cmd.arguments[1:]
+
+	handlers := map[string]func(*state.State, database.Feed, []string) error{
+		"rename":    renameFeed,
+		"set-url":   setFeedURL,
+		"delete":    deleteFeed,
+		"retention": setFeedRetention,
+	}
+	handler, ok := handlers[subcommand]
+	if !ok {
+		return fmt.Errorf("unknown feed subcommand: %v", subcommand)
+	}
+
+	feedDB, err := s.DB.GetFeedByUrl(context.Background(), args[0])
+	if err != nil {
+		return err
+	}
+	if subcommand == "delete" && user.Role != auth.RoleAdmin {
+		return errors.New("only admins can delete feeds")
+	}
+	if !canManageFeed(user, feedDB) {
+		return errors.New("only the user who added this feed or an admin can change it")
+	}
+
+	return handler(s, feedDB, args[1:])
+}
+
+func canManageFeed(user database.User, feed database.Feed) bool {
+	return user.Role == auth.RoleAdmin || (feed.UserID.Valid && feed.UserID.UUID == user.ID)
+}
+
+func renameFeed(s *state.State, feed database.Feed, args []string) error {
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		return errors.New("invalid arguments")
+	}
+
+	renamed, err := s.DB.RenameFeed(context.Background(), database.RenameFeedParams{
+		ID:        feed.ID,
+		Name:      strings.TrimSpace(args[0]),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "unique constraint \"feeds_name_key\"") {
+			return fmt.Errorf("a feed named %v already exists", args[0])
+		}
+		return err
+	}
+
+	fmt.Printf("feed %v renamed to %v\n", feed.Name, renamed.Name)
+	return nil
+}
+
+func setFeedURL(s *state.State, feed database.Feed, args []string) error {
+	if len(args) != 1 {
+		return errors.New("invalid arguments")
+	}
+
+	newURL := args[0]
+	if _, err := rss.FetchFeed(context.Background(), newURL); err != nil {
+		return err
+	}
+
+	updated, err := s.DB.SetFeedUrl(context.Background(), database.SetFeedUrlParams{
+		ID:        feed.ID,
+		Url:       newURL,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "unique constraint \"feeds_url_key\"") {
+			return fmt.Errorf("another feed already uses %v", newURL)
+		}
+		return err
+	}
+
+	fmt.Printf("feed %v now fetches %v\n", updated.Name, updated.Url)
+	return nil
+}
+
+func deleteFeed(s *state.State, feed database.Feed, args []string) error {
+	args, yes := takeYesFlag(args)
+	if len(args) != 0 {
+		return errors.New("invalid arguments")
+	}
+
+	postCount, err := s.DB.CountPostsForFeed(context.Background(), uuid.NullUUID{UUID: feed.ID, Valid: true})
+	if err != nil {
+		return err
+	}
+	followerCount, err := s.DB.CountFollowersForFeed(context.Background(), uuid.NullUUID{UUID: feed.ID, Valid: true})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("this deletes %v with %d posts, followed by %d users\n", feed.Name, postCount, followerCount)
+	if err := confirm("delete", yes); err != nil {
+		return err
+	}
+
+	// Follows and posts are removed along with the feed by ON DELETE CASCADE
+	if err := s.DB.DeleteFeed(context.Background(), feed.ID); err != nil {
+		return err
+	}
+
+	fmt.Printf("feed %v deleted along with %d posts\n", feed.Name, postCount)
+	return nil
+}
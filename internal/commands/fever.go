@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/fever"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+)
+
+// HandlerFever sets up Fever API access for the current user.
+//
+//	fever password <password>
+//	fever disable
+//
+// Fever clients log in with the gator username and this password.
+func HandlerFever(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) == 0 {
+		return errors.New("invalid arguments")
+	}
+
+	apiKey := sql.NullString{}
+	switch cmd.arguments[0] {
+	case "password":
+		if len(cmd.arguments) != 2 || cmd.arguments[1] == "" {
+			return errors.New("invalid arguments")
+		}
+		apiKey = sql.NullString{String: fever.APIKey(user.Name, cmd.arguments[1]), Valid: true}
+	case "disable":
+		if len(cmd.arguments) != 1 {
+			return errors.New("invalid arguments")
+		}
+	default:
+		return fmt.Errorf("unknown fever subcommand: %v", cmd.arguments[0])
+	}
+
+	if err := s.DB.SetUserFeverAPIKey(context.Background(), database.SetUserFeverAPIKeyParams{
+		ID:          user.ID,
+		FeverApiKey: apiKey,
+		UpdatedAt:   time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	if apiKey.Valid {
+		fmt.Printf("Fever access enabled, log in as %v with that password\n", user.Name)
+	} else {
+		fmt.Println("Fever access disabled")
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/auth"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/filter"
+	"github.com/acehotel33/bootdev-gator/internal/htmltext"
+	"github.com/acehotel33/bootdev-gator/internal/rss"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/google/uuid"
+)
+
+// HandlerFilter dispatches the filter rule subcommands:
+//
+//	filter add <include|exclude> <title|description|author|category|any> <keyword|regex> <pattern> [--feed <feed_url>] [--ingest]
+//	filter list
+//	filter test [limit]
+//	filter delete <rule_id>
+func HandlerFilter(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) == 0 {
+		return errors.New("invalid arguments")
+	}
+
+	args := cmd.arguments[1:]
+	switch cmd.arguments[0] {
+	case "add":
+		return addFilterRule(s, user, args)
+	case "list":
+		return listFilterRules(s, user, args)
+	case "test":
+		return testFilterRules(s, user, args)
+	case "delete":
+		return deleteFilterRule(s, user, args)
+	}
+	return fmt.Errorf("unknown filter subcommand: %v", cmd.arguments[0])
+}
+
+func addFilterRule(s *state.State, user database.User, args []string) error {
+	var positional []string
+	var feedID uuid.NullUUID
+	atIngest := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--ingest":
+			atIngest = true
+		case "--feed":
+			if i+1 >= len(args) {
+				return errors.New("--feed needs a feed URL")
+			}
+			i++
+			feedDB, err := s.DB.GetFeedByUrl(context.Background(), args[i])
+			if err != nil {
+				return err
+			}
+			feedID = uuid.NullUUID{UUID: feedDB.ID, Valid: true}
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+	if len(positional) != 4 {
+		return errors.New("invalid arguments")
+	}
+	if atIngest && user.Role != auth.RoleAdmin {
+		return errors.New("only admins can add ingest rules, since they drop posts for everyone")
+	}
+
+	// Build the rule first so bad fields or regular expressions are rejected
+	rule, err := filter.NewRule(uuid.New(), feedID, positional[0], positional[1], positional[2], positional[3])
+	if err != nil {
+		return err
+	}
+
+	ruleDB, err := s.DB.CreateFilterRule(context.Background(), database.CreateFilterRuleParams{
+		ID:        rule.ID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    rule.FeedID,
+		Action:    rule.Action,
+		Field:     rule.Field,
+		MatchType: rule.MatchType,
+		Pattern:   rule.Pattern,
+		AtIngest:  atIngest,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("filter rule %v added\n", shortID(ruleDB.ID))
+	return nil
+}
+
+func listFilterRules(s *state.State, user database.User, args []string) error {
+	if len(args) != 0 {
+		return errors.New("invalid arguments")
+	}
+
+	rules, err := s.DB.GetFilterRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		fmt.Println("no filter rules")
+		return nil
+	}
+
+	for _, rule := range rules {
+		line := fmt.Sprintf("%v %v %v %v %q", shortID(rule.ID), rule.Action, rule.Field, rule.MatchType, rule.Pattern)
+		if rule.FeedName.Valid {
+			line += " (feed: " + rule.FeedName.String + ")"
+		} else {
+			line += " (all feeds)"
+		}
+		if rule.AtIngest {
+			line += " [ingest]"
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func testFilterRules(s *state.State, user database.User, args []string) error {
+	if len(args) > 1 {
+		return errors.New("invalid arguments")
+	}
+
+	limit := 20
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return errors.New("invalid limit")
+		}
+		limit = n
+	}
+
+	rules, err := loadFilterRules(s, user.ID)
+	if err != nil {
+		return err
+	}
+
+	postsDB, err := s.DB.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return err
+	}
+
+	hidden := 0
+	for _, post := range postsDB {
+		shown, rule := filter.Evaluate(rules, filterPost(post))
+		verdict := "shown "
+		if !shown {
+			verdict = "hidden"
+			hidden++
+		}
+		reason := ""
+		switch {
+		case rule != nil:
+			reason = fmt.Sprintf(" (%v %v)", rule.Action, shortID(rule.ID))
+		case !shown:
+			reason = " (no include rule matched)"
+		}
+		fmt.Printf("%v %v%v\n", verdict, post.Title.String, reason)
+	}
+
+	fmt.Printf("%d of %d posts hidden\n", hidden, len(postsDB))
+	return nil
+}
+
+func deleteFilterRule(s *state.State, user database.User, args []string) error {
+	if len(args) != 1 {
+		return errors.New("invalid arguments")
+	}
+
+	rules, err := s.DB.GetFilterRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+	id, err := findByIDPrefix(ids, args[0])
+	if err != nil {
+		return fmt.Errorf("filter rule: %w", err)
+	}
+
+	if err := s.DB.DeleteFilterRule(context.Background(), database.DeleteFilterRuleParams{
+		ID:     id,
+		UserID: user.ID,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("filter rule %v deleted\n", shortID(id))
+	return nil
+}
+
+// loadFilterRules returns the user's filter rules for hiding posts when
+// they are read
+func loadFilterRules(s *state.State, userID uuid.UUID) ([]filter.Rule, error) {
+	return compileFilterRules(s.DB.GetFilterRulesToApply(context.Background(), userID))
+}
+
+// loadIngestRules returns the admins' ingest rules, which keep matching
+// items out of the shared posts table whoever runs agg
+func loadIngestRules(s *state.State) ([]filter.Rule, error) {
+	return compileFilterRules(s.DB.GetIngestFilterRules(context.Background()))
+}
+
+// compileFilterRules turns the result of a filter rule query into rules
+// that can be evaluated
+func compileFilterRules(rulesDB []database.FilterRule, err error) ([]filter.Rule, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]filter.Rule, 0, len(rulesDB))
+	for _, ruleDB := range rulesDB {
+		rule, err := filter.NewRule(ruleDB.ID, ruleDB.FeedID, ruleDB.Action, ruleDB.Field, ruleDB.MatchType, ruleDB.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("filter rule %v: %w", shortID(ruleDB.ID), err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func filterPost(post database.Post) filter.Post {
+	body := post.Description.String
+	if post.Content.Valid {
+		body = post.Content.String
+	}
+	return filter.Post{
+		FeedID:      post.FeedID.UUID,
+		Title:       post.Title.String,
+		Description: htmltext.Render(body, htmltext.Options{}),
+		Author:      post.Author.String,
+		Categories:  post.Categories,
+	}
+}
+
+func filterItem(feedID uuid.UUID, item rss.RSSItem) filter.Post {
+	body := item.Description
+	if item.Content != "" {
+		body = item.Content
+	}
+	return filter.Post{
+		FeedID:      feedID,
+		Title:       item.Title,
+		Description: htmltext.Render(body, htmltext.Options{}),
+		Author:      item.AuthorName(),
+		Categories:  item.Categories,
+	}
+}
+
+func shortID(id uuid.UUID) string {
+	return id.String()[:8]
+}
+
+// findByIDPrefix picks the one id starting with prefix, so rules can be
+// referred to by the short ids that list prints
+func findByIDPrefix(ids []uuid.UUID, prefix string) (uuid.UUID, error) {
+	var matches []uuid.UUID
+	for _, id := range ids {
+		if strings.HasPrefix(id.String(), strings.ToLower(prefix)) {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return uuid.UUID{}, errors.New("no match for that id")
+	case 1:
+		return matches[0], nil
+	}
+	return uuid.UUID{}, errors.New("more than one match for that id")
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/google/uuid"
+)
+
+// HandlerFolder files a followed feed under a folder, or takes it out of
+// its folder when no folder name is given
+//
+//	folder <feed_url> [folder_name]
+func HandlerFolder(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) == 0 || len(cmd.arguments) > 2 {
+		return errors.New("invalid arguments")
+	}
+
+	feedDB, err := s.DB.GetFeedByUrl(context.Background(), cmd.arguments[0])
+	if err != nil {
+		return err
+	}
+
+	folder := ""
+	if len(cmd.arguments) == 2 {
+		folder = strings.Trim(strings.TrimSpace(cmd.arguments[1]), "/")
+	}
+
+	_, err = s.DB.SetFeedFollowFolder(context.Background(), database.SetFeedFollowFolderParams{
+		UserID:    uuid.NullUUID{UUID: user.ID, Valid: true},
+		FeedID:    uuid.NullUUID{UUID: feedDB.ID, Valid: true},
+		Folder:    sql.NullString{String: folder, Valid: folder != ""},
+		UpdatedAt: time.Now(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("you are not following this feed")
+	}
+	if err != nil {
+		return err
+	}
+
+	if folder == "" {
+		fmt.Printf("%v removed from its folder\n", feedDB.Name)
+		return nil
+	}
+	fmt.Printf("%v moved to %v\n", feedDB.Name, folder)
+	return nil
+}
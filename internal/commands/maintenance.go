@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+)
+
+const defaultOrphanGracePeriod = 7 * 24 * time.Hour
+
+// HandlerGC reports feeds that nobody follows any more. With --delete,
+// feeds that have been orphaned for longer than the grace period are
+// removed together with their posts once confirmed.
+//
+//	gc [grace_period] [--delete] [--yes]
+func HandlerGC(s *state.State, cmd Command, user database.User) error {
+	args, yes := takeYesFlag(cmd.arguments)
+	if len(args) > 2 {
+		return errors.New("invalid arguments")
+	}
+
+	gracePeriod := defaultOrphanGracePeriod
+	deleteFeeds := false
+	for _, arg := range args {
+		if arg == "--delete" {
+			deleteFeeds = true
+			continue
+		}
+		timeDuration, err := time.ParseDuration(arg)
+		if err != nil {
+			return err
+		}
+		gracePeriod = timeDuration
+	}
+
+	if err := s.DB.ClearFollowedFeedsOrphanedAt(context.Background()); err != nil {
+		return err
+	}
+	if err := s.DB.MarkOrphanedFeeds(context.Background(), sql.NullTime{Time: time.Now(), Valid: true}); err != nil {
+		return err
+	}
+
+	orphans, err := s.DB.GetOrphanedFeeds(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		fmt.Println("no orphaned feeds")
+		return nil
+	}
+
+	cutoff := time.Now().Add(-gracePeriod)
+	var expired []database.GetOrphanedFeedsRow
+	var expiredPosts, expiredBytes int64
+	for _, feed := range orphans {
+		fmt.Printf("* %v (%v): %d posts, %v, orphaned since %v\n",
+			feed.Name, feed.Url, feed.PostCount, formatBytes(feed.PostBytes), feed.OrphanedAt.Time.Format(time.DateTime))
+
+		if feed.OrphanedAt.Time.Before(cutoff) {
+			expired = append(expired, feed)
+			expiredPosts += feed.PostCount
+			expiredBytes += feed.PostBytes
+		}
+	}
+
+	if !deleteFeeds {
+		fmt.Printf("%d orphaned feeds, run with --delete to remove those orphaned for more than %v\n", len(orphans), gracePeriod)
+		return nil
+	}
+	if len(expired) == 0 {
+		fmt.Printf("no feeds orphaned for more than %v\n", gracePeriod)
+		return nil
+	}
+
+	fmt.Printf("this deletes %d feeds and %d posts (%v)\n", len(expired), expiredPosts, formatBytes(expiredBytes))
+	if err := confirm("delete", yes); err != nil {
+		return err
+	}
+	for _, feed := range expired {
+		if err := s.DB.DeleteFeed(context.Background(), feed.ID); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("deleted %d feeds and %d posts, reclaimed %v\n", len(expired), expiredPosts, formatBytes(expiredBytes))
+	return nil
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/auth"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/opml"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/google/uuid"
+)
+
+func HandlerImport(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) != 1 {
+		return errors.New("invalid arguments")
+	}
+
+	file, err := os.Open(cmd.arguments[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	feeds, err := opml.Parse(file)
+	if err != nil {
+		return fmt.Errorf("could not parse OPML file: %w", err)
+	}
+
+	imported, failed := 0, 0
+	for _, feed := range feeds {
+		if err := importFeed(s, user, feed); err != nil {
+			fmt.Printf("failed: %v (%v): %v\n", feed.Title, feed.XMLURL, err)
+			failed++
+			continue
+		}
+
+		line := "imported: " + feed.Title
+		if feed.Folder != "" {
+			line += " [" + feed.Folder + "]"
+		}
+		fmt.Println(line)
+		imported++
+	}
+
+	fmt.Printf("%d feeds imported, %d failed\n", imported, failed)
+	return nil
+}
+
+func importFeed(s *state.State, user database.User, feed opml.Feed) error {
+	feedURL, err := url.Parse(feed.XMLURL)
+	if err != nil || (feedURL.Scheme != "http" && feedURL.Scheme != "https") {
+		return errors.New("invalid feed URL")
+	}
+
+	feedDB, err := s.DB.GetFeedByUrl(context.Background(), feed.XMLURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		feedName := feed.Title
+		if feedName == "" {
+			feedName = feedURL.Host
+		}
+
+		feedDB, err = createFeedWithUniqueName(s, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Name:      feedName,
+			Url:       feed.XMLURL,
+			UserID: uuid.NullUUID{
+				UUID:  user.ID,
+				Valid: true,
+			},
+			SiteUrl: sql.NullString{String: feed.HTMLURL, Valid: feed.HTMLURL != ""},
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID: uuid.NullUUID{
+			UUID:  user.ID,
+			Valid: true,
+		},
+		FeedID: uuid.NullUUID{
+			UUID:  feedDB.ID,
+			Valid: true,
+		},
+	})
+	if err != nil && !strings.Contains(err.Error(), "unique_user_feed_pair") {
+		return err
+	}
+
+	if feed.Folder == "" {
+		return nil
+	}
+	_, err = s.DB.SetFeedFollowFolder(context.Background(), database.SetFeedFollowFolderParams{
+		UserID:    uuid.NullUUID{UUID: user.ID, Valid: true},
+		FeedID:    uuid.NullUUID{UUID: feedDB.ID, Valid: true},
+		Folder:    sql.NullString{String: feed.Folder, Valid: true},
+		UpdatedAt: time.Now(),
+	})
+	return err
+}
+
+// HandlerExport writes followed feeds as OPML. With --all, admins export
+// every user's follows, each user's feeds in a folder named after them.
+//
+//	export [--all] [file.opml]
+func HandlerExport(s *state.State, cmd Command, user database.User) error {
+	var args []string
+	all := false
+	for _, arg := range cmd.arguments {
+		if arg == "--all" {
+			all = true
+			continue
+		}
+		args = append(args, arg)
+	}
+	if len(args) > 1 {
+		return errors.New("invalid arguments")
+	}
+	if all && user.Role != auth.RoleAdmin {
+		return errors.New("only admins can export every user's feeds")
+	}
+
+	var feeds []opml.Feed
+	title := fmt.Sprintf("gator subscriptions for %v", user.Name)
+	if all {
+		following, err := s.DB.GetAllFeedFollows(context.Background())
+		if err != nil {
+			return err
+		}
+		for _, follow := range following {
+			folder := follow.UserName
+			if follow.Folder.Valid {
+				folder += "/" + follow.Folder.String
+			}
+			feeds = append(feeds, opml.Feed{
+				Title:   follow.FeedName,
+				XMLURL:  follow.FeedUrl,
+				HTMLURL: follow.FeedSiteUrl.String,
+				Folder:  folder,
+			})
+		}
+		title = "gator subscriptions for all users"
+	} else {
+		following, err := s.DB.GetFeedFollowsForUser(context.Background(), uuid.NullUUID{
+			UUID:  user.ID,
+			Valid: true,
+		})
+		if err != nil {
+			return err
+		}
+		for _, follow := range following {
+			feeds = append(feeds, opml.Feed{
+				Title:   follow.FeedName,
+				XMLURL:  follow.FeedUrl,
+				HTMLURL: follow.FeedSiteUrl.String,
+				Folder:  follow.Folder.String,
+			})
+		}
+	}
+
+	if len(args) == 0 {
+		return opml.Write(os.Stdout, title, feeds)
+	}
+
+	file, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	if err := opml.Write(file, title, feeds); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("exported %d feeds to %v\n", len(feeds), args[0])
+	return nil
+}
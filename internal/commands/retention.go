@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/google/uuid"
+)
+
+// pruneInterval is how often agg runs a retention pass between fetches
+const pruneInterval = time.Hour
+
+func HandlerPrune(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) != 0 {
+		return errors.New("invalid arguments")
+	}
+
+	removed, err := prunePosts(s)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("pruned %d posts\n", removed)
+	return nil
+}
+
+// prunePosts applies each feed's retention policy, falling back to the
+// default policy, and records the run. Starred posts are never removed and
+// pruned posts are remembered so fetching doesn't add them back.
+func prunePosts(s *state.State) (int64, error) {
+	defaults, err := retentionDefaults(s)
+	if err != nil {
+		return 0, err
+	}
+	defaultMaxAge := time.Duration(defaults.MaxAgeHours.Int32) * time.Hour
+	defaultMaxCount := int(defaults.MaxCount.Int32)
+
+	feeds, err := s.DB.GetFeeds(context.Background())
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, feed := range feeds {
+		maxAge := defaultMaxAge
+		if feed.RetentionMaxAgeHours.Valid {
+			maxAge = time.Duration(feed.RetentionMaxAgeHours.Int32) * time.Hour
+		}
+		maxCount := defaultMaxCount
+		if feed.RetentionMaxCount.Valid {
+			maxCount = int(feed.RetentionMaxCount.Int32)
+		}
+		if maxAge <= 0 && maxCount <= 0 {
+			continue
+		}
+
+		removed, err := s.DB.PrunePostsForFeed(context.Background(), database.PrunePostsForFeedParams{
+			FeedID:          uuid.NullUUID{UUID: feed.ID, Valid: true},
+			PublishedBefore: sql.NullTime{Time: time.Now().Add(-maxAge), Valid: maxAge > 0},
+			KeepCount:       sql.NullInt32{Int32: int32(maxCount), Valid: maxCount > 0},
+			PrunedAt:        time.Now(),
+		})
+		if err != nil {
+			return total, err
+		}
+		if removed > 0 {
+			fmt.Printf("pruned %d posts from %v\n", removed, feed.Name)
+		}
+		total += removed
+	}
+
+	_, err = s.DB.CreatePruneRun(context.Background(), database.CreatePruneRunParams{
+		ID:           uuid.New(),
+		CreatedAt:    time.Now(),
+		RemovedPosts: int32(total),
+	})
+	return total, err
+}
+
+// retentionDefaults returns the default retention policy, which has no
+// limits until an admin sets one
+func retentionDefaults(s *state.State) (database.RetentionDefault, error) {
+	defaults, err := s.DB.GetRetentionDefaults(context.Background())
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.RetentionDefault{}, nil
+	}
+	return defaults, err
+}
+
+// HandlerRetention shows the default retention policy, or sets it when
+// given limits. "-" and 0 both mean no limit.
+//
+//	retention [max_age_hours max_count]
+func HandlerRetention(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) == 0 {
+		defaults, err := retentionDefaults(s)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("default retention: max age %v, max count %v\n", describeDefault(defaults.MaxAgeHours, "h"), describeDefault(defaults.MaxCount, ""))
+		return nil
+	}
+	if len(cmd.arguments) != 2 {
+		return errors.New("invalid arguments")
+	}
+
+	maxAgeHours, err := parseRetentionLimit(cmd.arguments[0])
+	if err != nil {
+		return err
+	}
+	maxCount, err := parseRetentionLimit(cmd.arguments[1])
+	if err != nil {
+		return err
+	}
+
+	defaults, err := s.DB.SetRetentionDefaults(context.Background(), database.SetRetentionDefaultsParams{
+		MaxAgeHours: maxAgeHours,
+		MaxCount:    maxCount,
+		UpdatedAt:   time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("default retention: max age %v, max count %v\n", describeDefault(defaults.MaxAgeHours, "h"), describeDefault(defaults.MaxCount, ""))
+	return nil
+}
+
+func describeDefault(limit sql.NullInt32, unit string) string {
+	if !limit.Valid || limit.Int32 == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d%v", limit.Int32, unit)
+}
+
+// setFeedRetention handles feed retention <feed_url> <max_age_hours|-> <max_count|->,
+// where "-" falls back to the default policy
+func setFeedRetention(s *state.State, feed database.Feed, args []string) error {
+	if len(args) != 2 {
+		return errors.New("invalid arguments")
+	}
+
+	maxAgeHours, err := parseRetentionLimit(args[0])
+	if err != nil {
+		return err
+	}
+	maxCount, err := parseRetentionLimit(args[1])
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.SetFeedRetention(context.Background(), database.SetFeedRetentionParams{
+		ID:                   feed.ID,
+		RetentionMaxAgeHours: maxAgeHours,
+		RetentionMaxCount:    maxCount,
+		UpdatedAt:            time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("retention for %v: max age %v, max count %v\n", feed.Name, describeLimit(maxAgeHours, "h"), describeLimit(maxCount, ""))
+	return nil
+}
+
+func parseRetentionLimit(arg string) (sql.NullInt32, error) {
+	if arg == "-" {
+		return sql.NullInt32{}, nil
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 0 {
+		return sql.NullInt32{}, fmt.Errorf("invalid retention limit: %v", arg)
+	}
+	return sql.NullInt32{Int32: int32(n), Valid: true}, nil
+}
+
+func describeLimit(limit sql.NullInt32, unit string) string {
+	if !limit.Valid {
+		return "default"
+	}
+	if limit.Int32 == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d%v", limit.Int32, unit)
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/api"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/fever"
+	"github.com/acehotel33/bootdev-gator/internal/greader"
+	"github.com/acehotel33/bootdev-gator/internal/publish"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/acehotel33/bootdev-gator/internal/web"
+	"github.com/google/uuid"
+)
+
+const defaultServeAddr = "localhost:8080"
+
+// HandlerServe runs the web UI until the process is stopped.
+//
+//	serve [addr]
+func HandlerServe(s *state.State, cmd Command) error {
+	if len(cmd.arguments) > 1 {
+		return errors.New("invalid arguments")
+	}
+
+	addr := defaultServeAddr
+	if len(cmd.arguments) == 1 {
+		addr = cmd.arguments[0]
+	}
+
+	handler, err := newServer(s)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      2 * time.Minute,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	fmt.Printf("serving gator on http://%v\n", addr)
+	return server.ListenAndServe()
+}
+
+// newServer builds the web UI, the REST API and the Fever API with the
+// same feed logic the commands use
+func newServer(s *state.State) (http.Handler, error) {
+	addFeed := func(ctx context.Context, user database.User, name, feedURL string) (database.Feed, error) {
+		return addOrFollowFeed(s, user, name, feedURL)
+	}
+
+	webSrv, err := web.New(s.DB, addFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(api.Prefix+"/", api.New(s.DB, addFeed))
+	// The timeline is fetched by feed readers, which can't log in to the
+	// web UI, so it takes an API key instead of the session cookie
+	mux.Handle("GET /timeline.xml", timelineHandler(s, timelineKeyUser(s)))
+	mux.Handle("/fever/", fever.New(s.DB))
+	greaderSrv := greader.New(s.DB)
+	mux.Handle("/accounts/ClientLogin", greaderSrv)
+	mux.Handle("/reader/api/0/", greaderSrv)
+	mux.Handle("/", webSrv)
+	return mux, nil
+}
+
+// timelineKeyUser authenticates timeline requests with an API key sent as
+// "Authorization: Bearer <key>" or, for readers that can only take a URL,
+// in the "key" query parameter
+func timelineKeyUser(s *state.State) func(r *http.Request) (database.User, error) {
+	return func(r *http.Request) (database.User, error) {
+		key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			key = r.URL.Query().Get("key")
+		}
+		if strings.TrimSpace(key) == "" {
+			return database.User{}, fmt.Errorf("%w: missing API key", publish.ErrUnauthorized)
+		}
+
+		user, err := api.UserForKey(r.Context(), s.DB, key)
+		if errors.Is(err, api.ErrInvalidKey) {
+			return database.User{}, fmt.Errorf("%w: %v", publish.ErrUnauthorized, err)
+		}
+		return user, err
+	}
+}
+
+// addOrFollowFeed follows feedURL when gator already knows the feed, and
+// otherwise adds it like addfeed without prompting
+func addOrFollowFeed(s *state.State, user database.User, name, feedURL string) (database.Feed, error) {
+	feedDB, err := s.DB.GetFeedByUrl(context.Background(), feedURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		return addFeed(s, user, name, feedURL, firstFeedLink)
+	}
+	if err != nil {
+		return database.Feed{}, err
+	}
+
+	_, err = s.DB.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    uuid.NullUUID{UUID: user.ID, Valid: true},
+		FeedID:    uuid.NullUUID{UUID: feedDB.ID, Valid: true},
+	})
+	if err != nil && !strings.Contains(err.Error(), "unique_user_feed_pair") {
+		return database.Feed{}, err
+	}
+	return feedDB, nil
+}
@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/publish"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/google/uuid"
+)
+
+const defaultTimelineLimit = 50
+
+// timelineOptions selects which of a user's posts are republished
+type timelineOptions struct {
+	Folder      string
+	StarredOnly bool
+	Limit       int32
+}
+
+// HandlerTimeline writes the user's timeline as a feed other readers can
+// subscribe to.
+//
+//	timeline [--format rss|atom] [--folder <folder>] [--starred] [--limit <n>] [file]
+func HandlerTimeline(s *state.State, cmd Command, user database.User) error {
+	format := publish.FormatRSS
+	opts := timelineOptions{Limit: defaultTimelineLimit}
+	var positional []string
+
+	for i := 0; i < len(cmd.arguments); i++ {
+		arg := cmd.arguments[i]
+		switch arg {
+		case "--starred":
+			opts.StarredOnly = true
+			continue
+		case "--format", "--folder", "--limit":
+		default:
+			positional = append(positional, arg)
+			continue
+		}
+
+		if i+1 >= len(cmd.arguments) {
+			return fmt.Errorf("%v needs a value", arg)
+		}
+		i++
+		value := cmd.arguments[i]
+		switch arg {
+		case "--format":
+			format = value
+		case "--folder":
+			opts.Folder = value
+		case "--limit":
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				return errors.New("invalid limit")
+			}
+			opts.Limit = int32(limit)
+		}
+	}
+	if len(positional) > 1 {
+		return errors.New("invalid arguments")
+	}
+	if format != publish.FormatRSS && format != publish.FormatAtom {
+		return publish.ErrUnknownFormat
+	}
+
+	feed, err := timelineFeed(s, user, opts)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) == 0 {
+		return publish.Write(os.Stdout, format, feed)
+	}
+
+	file, err := os.Create(positional[0])
+	if err != nil {
+		return err
+	}
+	if err := publish.Write(file, format, feed); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %d posts to %v\n", len(feed.Items), positional[0])
+	return nil
+}
+
+// timelineFeed builds the feed of the user's latest posts. Link and Self
+// are left for callers that know where the feed is served from.
+func timelineFeed(s *state.State, user database.User, opts timelineOptions) (publish.Feed, error) {
+	postsDB, err := s.DB.GetTimelineForUser(context.Background(), database.GetTimelineForUserParams{
+		UserID:      uuid.NullUUID{UUID: user.ID, Valid: true},
+		Folder:      sql.NullString{String: opts.Folder, Valid: opts.Folder != ""},
+		StarredOnly: opts.StarredOnly,
+		RowLimit:    opts.Limit,
+	})
+	if err != nil {
+		return publish.Feed{}, err
+	}
+
+	title := fmt.Sprintf("gator timeline for %v", user.Name)
+	if opts.Folder != "" {
+		title += " (" + opts.Folder + ")"
+	}
+	if opts.StarredOnly {
+		title += " (starred)"
+	}
+
+	feed := publish.Feed{
+		ID:          "urn:uuid:" + user.ID.String(),
+		Title:       title,
+		Description: "Posts from the feeds " + user.Name + " follows in gator",
+		Author:      user.Name,
+	}
+
+	for _, post := range postsDB {
+		item := publish.Item{
+			ID:          "urn:uuid:" + post.ID.String(),
+			Title:       post.Title.String,
+			Link:        post.Url,
+			Description: post.Description.String,
+			Content:     post.Content.String,
+			Author:      post.Author.String,
+			Categories:  post.Categories,
+			SourceName:  post.FeedName,
+			SourceURL:   post.FeedUrl,
+			Published:   post.CreatedAt,
+		}
+		if post.PublishedAt.Valid {
+			item.Published = post.PublishedAt.Time
+		}
+
+		if item.Published.After(feed.Updated) {
+			feed.Updated = item.Published
+		}
+		feed.Items = append(feed.Items, item)
+	}
+
+	return feed, nil
+}
+
+// timelineHandler serves a user's timeline over HTTP for server modes.
+// The folder, starred, limit and format query parameters mirror the
+// timeline command's flags.
+func timelineHandler(s *state.State, userFor func(r *http.Request) (database.User, error)) http.Handler {
+	return publish.Handler(func(r *http.Request) (publish.Feed, error) {
+		user, err := userFor(r)
+		if err != nil {
+			return publish.Feed{}, err
+		}
+
+		query := r.URL.Query()
+		opts := timelineOptions{
+			Folder:      query.Get("folder"),
+			StarredOnly: query.Get("starred") == "true" || query.Get("starred") == "1",
+			Limit:       defaultTimelineLimit,
+		}
+		if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit <= maxBrowseFetch {
+			opts.Limit = int32(limit)
+		}
+
+		feed, err := timelineFeed(s, user, opts)
+		if err != nil {
+			return publish.Feed{}, err
+		}
+
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		feed.Link = scheme + "://" + r.Host + "/"
+		// Keep the API key out of the published document
+		self := *r.URL
+		query.Del("key")
+		self.RawQuery = query.Encode()
+		feed.Self = scheme + "://" + r.Host + self.RequestURI()
+		return feed, nil
+	})
+}
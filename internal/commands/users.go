@@ -0,0 +1,307 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/auth"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/google/uuid"
+)
+
+// HandlerUser dispatches the user management subcommands:
+//
+//	user rename [username] <new_name>
+//	user delete [username] [--transfer-to <username>|--delete-feeds] [--yes]
+//	user role <username> <admin|member>
+//	user passwd <username>
+//
+// Without a username rename and delete act on the current user. Only
+// admins can name someone else.
+func HandlerUser(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) == 0 {
+		return errors.New("invalid arguments")
+	}
+
+	args := cmd.arguments[1:]
+	switch cmd.arguments[0] {
+	case "rename":
+		return renameUser(s, user, args)
+	case "delete":
+		return deleteUser(s, user, args)
+	case "role":
+		return setUserRole(s, user, args)
+	case "passwd":
+		return setUserPassword(s, user, args)
+	}
+	return fmt.Errorf("unknown user subcommand: %v", cmd.arguments[0])
+}
+
+// targetUser returns the user named by username, or the current user when
+// it is empty. Only admins can act on other users.
+func targetUser(s *state.State, user database.User, username string) (database.User, error) {
+	if username == "" || username == user.Name {
+		return user, nil
+	}
+	if user.Role != auth.RoleAdmin {
+		return database.User{}, errors.New("only admins can manage other users")
+	}
+
+	target, err := s.DB.GetUser(context.Background(), username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, fmt.Errorf("unknown user %v", username)
+	}
+	return target, err
+}
+
+func renameUser(s *state.State, user database.User, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New("invalid arguments")
+	}
+
+	username := ""
+	if len(args) == 2 {
+		username = args[0]
+	}
+	newName := strings.TrimSpace(args[len(args)-1])
+	if newName == "" {
+		return errors.New("new name can't be empty")
+	}
+
+	target, err := targetUser(s, user, username)
+	if err != nil {
+		return err
+	}
+
+	renamed, err := s.DB.RenameUser(context.Background(), database.RenameUserParams{
+		ID:        target.ID,
+		Name:      newName,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "unique constraint \"users_name_key\"") {
+			return fmt.Errorf("a user named %v already exists", newName)
+		}
+		return err
+	}
+
+	if target.ID == user.ID {
+		if err := s.Cfg.SetSession(renamed.Name, s.Cfg.SessionToken); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("user %v renamed to %v\n", target.Name, renamed.Name)
+	if target.FeverApiKey.Valid {
+		fmt.Println("Fever access has been turned off, run `gator fever password` to set it up again")
+	}
+	return nil
+}
+
+func deleteUser(s *state.State, user database.User, args []string) error {
+	args, yes := takeYesFlag(args)
+
+	var positional []string
+	transferTo := ""
+	deleteFeeds := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--delete-feeds":
+			deleteFeeds = true
+		case "--transfer-to":
+			if i+1 >= len(args) {
+				return errors.New("--transfer-to needs a username")
+			}
+			transferTo = args[i+1]
+			i++
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+	if len(positional) > 1 {
+		return errors.New("invalid arguments")
+	}
+	if deleteFeeds && transferTo != "" {
+		return errors.New("use either --transfer-to or --delete-feeds, not both")
+	}
+
+	username := ""
+	if len(positional) == 1 {
+		username = positional[0]
+	}
+	target, err := targetUser(s, user, username)
+	if err != nil {
+		return err
+	}
+
+	if target.Role == auth.RoleAdmin {
+		admins, err := s.DB.CountAdmins(context.Background())
+		if err != nil {
+			return err
+		}
+		userCount, err := s.DB.CountUsers(context.Background())
+		if err != nil {
+			return err
+		}
+		if admins <= 1 && userCount > 1 {
+			return errors.New("can't delete the last admin, make another user an admin first")
+		}
+	}
+
+	targetID := uuid.NullUUID{UUID: target.ID, Valid: true}
+	feedCount, err := s.DB.CountFeedsForUser(context.Background(), targetID)
+	if err != nil {
+		return err
+	}
+
+	// Feeds are shared with their followers, so don't drop them silently
+	var newOwner database.User
+	switch {
+	case transferTo != "":
+		newOwner, err = s.DB.GetUser(context.Background(), transferTo)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("unknown user %v", transferTo)
+		}
+		if err != nil {
+			return err
+		}
+		if newOwner.ID == target.ID {
+			return errors.New("can't transfer feeds to the user being deleted")
+		}
+		fmt.Printf("this deletes user %v and gives their %d feeds to %v\n", target.Name, feedCount, newOwner.Name)
+	case feedCount > 0 && !deleteFeeds:
+		return fmt.Errorf("%v added %d feeds, choose --transfer-to <username> or --delete-feeds", target.Name, feedCount)
+	default:
+		fmt.Printf("this deletes user %v and their %d feeds with all their posts\n", target.Name, feedCount)
+	}
+	if err := confirm(target.Name, yes); err != nil {
+		return err
+	}
+
+	// Transfer and delete together, so a failed delete doesn't leave the
+	// feeds with their new owner
+	tx, err := s.Conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := s.DB.WithTx(tx)
+
+	if transferTo != "" {
+		_, err := qtx.TransferFeeds(context.Background(), database.TransferFeedsParams{
+			ToUserID:   uuid.NullUUID{UUID: newOwner.ID, Valid: true},
+			UpdatedAt:  time.Now(),
+			FromUserID: targetID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Follows, sessions, API keys, rules and webhooks go with the user by
+	// ON DELETE CASCADE, and so do any feeds that weren't transferred
+	if err := qtx.DeleteUser(context.Background(), target.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if target.ID == user.ID {
+		if err := s.Cfg.SetSession("", ""); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("user %v deleted\n", target.Name)
+	return nil
+}
+
+func setUserRole(s *state.State, user database.User, args []string) error {
+	if len(args) != 2 {
+		return errors.New("invalid arguments")
+	}
+	if user.Role != auth.RoleAdmin {
+		return errors.New("only admins can change roles")
+	}
+
+	role := args[1]
+	if role != auth.RoleAdmin && role != auth.RoleMember {
+		return fmt.Errorf("unknown role %q, expected admin or member", role)
+	}
+
+	target, err := s.DB.GetUser(context.Background(), args[0])
+	if err != nil {
+		return fmt.Errorf("unknown user %v", args[0])
+	}
+	if target.Role == role {
+		fmt.Printf("%v is already %v\n", target.Name, role)
+		return nil
+	}
+
+	if target.Role == auth.RoleAdmin {
+		admins, err := s.DB.CountAdmins(context.Background())
+		if err != nil {
+			return err
+		}
+		if admins <= 1 {
+			return errors.New("can't demote the last admin")
+		}
+	}
+
+	err = s.DB.SetUserRole(context.Background(), database.SetUserRoleParams{
+		ID:        target.ID,
+		Role:      role,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v is now %v\n", target.Name, role)
+	return nil
+}
+
+// setUserPassword lets an admin give another user a new password, which is
+// how users registered before passwords existed get one. Their sessions are
+// logged out.
+func setUserPassword(s *state.State, user database.User, args []string) error {
+	if len(args) != 1 {
+		return errors.New("invalid arguments")
+	}
+	if user.Role != auth.RoleAdmin {
+		return errors.New("only admins can set other users' passwords, use passwd for your own")
+	}
+	if args[0] == user.Name {
+		return errors.New("use passwd to change your own password")
+	}
+
+	target, err := targetUser(s, user, args[0])
+	if err != nil {
+		return err
+	}
+
+	hash, err := readNewPassword(fmt.Sprintf("new password for %v: ", target.Name))
+	if err != nil {
+		return err
+	}
+	err = s.DB.SetUserPassword(context.Background(), database.SetUserPasswordParams{
+		ID:           target.ID,
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+		UpdatedAt:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.DB.DeleteSessionsForUser(context.Background(), target.ID); err != nil {
+		return err
+	}
+
+	fmt.Printf("password for %v set, their sessions have been logged out\n", target.Name)
+	return nil
+}
@@ -0,0 +1,318 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/auth"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/htmltext"
+	"github.com/acehotel33/bootdev-gator/internal/state"
+	"github.com/acehotel33/bootdev-gator/internal/webhook"
+	"github.com/google/uuid"
+)
+
+// webhookBatchSize caps how many deliveries are sent per aggregator tick
+const webhookBatchSize = 50
+
+// HandlerWebhook dispatches the webhook subcommands:
+//
+//	webhook add <url> [--secret <secret>] [--feed <feed_url>] [--global]
+//	webhook list
+//	webhook delete <webhook_id>
+//	webhook failed
+//	webhook retry <delivery_id>
+func HandlerWebhook(s *state.State, cmd Command, user database.User) error {
+	if len(cmd.arguments) == 0 {
+		return errors.New("invalid arguments")
+	}
+
+	args := cmd.arguments[1:]
+	switch cmd.arguments[0] {
+	case "add":
+		return addWebhook(s, user, args)
+	case "list":
+		return listWebhooks(s, user, args)
+	case "delete":
+		return deleteWebhook(s, user, args)
+	case "failed":
+		return listFailedDeliveries(s, user, args)
+	case "retry":
+		return retryDelivery(s, user, args)
+	}
+	return fmt.Errorf("unknown webhook subcommand: %v", cmd.arguments[0])
+}
+
+func addWebhook(s *state.State, user database.User, args []string) error {
+	var positional []string
+	var feedID uuid.NullUUID
+	secret := ""
+	global := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--global":
+			global = true
+		case "--secret", "--feed":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%v needs a value", args[i])
+			}
+			if args[i] == "--secret" {
+				secret = args[i+1]
+			} else {
+				feedDB, err := s.DB.GetFeedByUrl(context.Background(), args[i+1])
+				if err != nil {
+					return err
+				}
+				feedID = uuid.NullUUID{UUID: feedDB.ID, Valid: true}
+			}
+			i++
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+	if len(positional) != 1 {
+		return errors.New("invalid arguments")
+	}
+	if global && user.Role != auth.RoleAdmin {
+		return errors.New("only admins can add global webhooks")
+	}
+
+	hookURL, err := url.Parse(positional[0])
+	if err != nil || (hookURL.Scheme != "http" && hookURL.Scheme != "https") || hookURL.Host == "" {
+		return errors.New("webhook URL must be an http or https URL")
+	}
+
+	generated := secret == ""
+	if generated {
+		secret, err = webhook.NewSecret()
+		if err != nil {
+			return err
+		}
+	}
+
+	hookDB, err := s.DB.CreateWebhook(context.Background(), database.CreateWebhookParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feedID,
+		Url:       hookURL.String(),
+		Secret:    secret,
+		Global:    global,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("webhook %v added\n", shortID(hookDB.ID))
+	if generated {
+		fmt.Printf("signing secret: %v\n", secret)
+	}
+	return nil
+}
+
+func listWebhooks(s *state.State, user database.User, args []string) error {
+	if len(args) != 0 {
+		return errors.New("invalid arguments")
+	}
+
+	hooks, err := s.DB.GetWebhooksForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		fmt.Println("no webhooks")
+		return nil
+	}
+
+	for _, hook := range hooks {
+		scope := "followed feeds"
+		if hook.Global {
+			scope = "all feeds"
+		}
+		if hook.FeedName.Valid {
+			scope = "feed: " + hook.FeedName.String
+		}
+		fmt.Printf("%v %v (%v)\n", shortID(hook.ID), hook.Url, scope)
+	}
+	return nil
+}
+
+func deleteWebhook(s *state.State, user database.User, args []string) error {
+	if len(args) != 1 {
+		return errors.New("invalid arguments")
+	}
+
+	hooks, err := s.DB.GetWebhooksForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, 0, len(hooks))
+	for _, hook := range hooks {
+		ids = append(ids, hook.ID)
+	}
+	id, err := findByIDPrefix(ids, args[0])
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+
+	if err := s.DB.DeleteWebhook(context.Background(), database.DeleteWebhookParams{
+		ID:     id,
+		UserID: user.ID,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("webhook %v deleted\n", shortID(id))
+	return nil
+}
+
+func listFailedDeliveries(s *state.State, user database.User, args []string) error {
+	if len(args) != 0 {
+		return errors.New("invalid arguments")
+	}
+
+	deliveries, err := s.DB.GetFailedWebhookDeliveriesForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	if len(deliveries) == 0 {
+		fmt.Println("no failed deliveries")
+		return nil
+	}
+
+	for _, delivery := range deliveries {
+		fmt.Printf("%v %v -> %v\n", shortID(delivery.ID), delivery.PostTitle.String, delivery.WebhookUrl)
+		fmt.Printf("    failed %v after %d attempts: %v\n", delivery.FailedAt.Time.Format(time.RFC1123), delivery.Attempts, delivery.LastError.String)
+	}
+	return nil
+}
+
+func retryDelivery(s *state.State, user database.User, args []string) error {
+	if len(args) != 1 {
+		return errors.New("invalid arguments")
+	}
+
+	deliveries, err := s.DB.GetFailedWebhookDeliveriesForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.ID)
+	}
+	id, err := findByIDPrefix(ids, args[0])
+	if err != nil {
+		return fmt.Errorf("failed delivery: %w", err)
+	}
+
+	if err := s.DB.RetryWebhookDelivery(context.Background(), database.RetryWebhookDeliveryParams{
+		ID:            id,
+		NextAttemptAt: time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("delivery %v queued again, agg will send it on its next run\n", shortID(id))
+	return nil
+}
+
+// enqueueWebhooks queues a delivery of post to every webhook subscribed to
+// its feed. The payload is stored so retries send exactly the same body.
+func enqueueWebhooks(s *state.State, hooks []database.Webhook, feed database.Feed, post database.Post) {
+	if len(hooks) == 0 {
+		return
+	}
+
+	body := post.Description.String
+	if post.Content.Valid {
+		body = post.Content.String
+	}
+	payload := webhook.Payload{
+		Event:     webhook.EventPostCreated,
+		CreatedAt: post.CreatedAt.UTC(),
+		Feed:      webhook.Feed{ID: feed.ID.String(), Name: feed.Name, URL: feed.Url},
+		Post: webhook.Post{
+			ID:          post.ID.String(),
+			Title:       post.Title.String,
+			URL:         post.Url,
+			Description: htmltext.Render(body, htmltext.Options{}),
+			Author:      post.Author.String,
+			Categories:  post.Categories,
+		},
+	}
+	if post.PublishedAt.Valid {
+		payload.Post.PublishedAt = &post.PublishedAt.Time
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, hook := range hooks {
+		_, err := s.DB.CreateWebhookDelivery(context.Background(), database.CreateWebhookDeliveryParams{
+			ID:            uuid.New(),
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+			WebhookID:     hook.ID,
+			PostID:        post.ID,
+			Payload:       string(encoded),
+			NextAttemptAt: time.Now(),
+		})
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+// deliverWebhooks sends the queued deliveries that are due. Failures are
+// retried with exponential backoff until webhook.MaxAttempts is reached.
+func deliverWebhooks(s *state.State) error {
+	deliveries, err := s.DB.GetDueWebhookDeliveries(context.Background(), database.GetDueWebhookDeliveriesParams{
+		NextAttemptAt: time.Now(),
+		Limit:         webhookBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		now := time.Now()
+		outcome := webhook.Attempt(context.Background(), delivery.WebhookUrl, delivery.WebhookSecret, delivery.ID.String(), []byte(delivery.Payload), int(delivery.Attempts), now)
+		if outcome.Err == nil {
+			if err := s.DB.MarkWebhookDelivered(context.Background(), database.MarkWebhookDeliveredParams{
+				ID:          delivery.ID,
+				DeliveredAt: sql.NullTime{Time: now, Valid: true},
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		failedAt := sql.NullTime{}
+		if outcome.GaveUp {
+			failedAt = sql.NullTime{Time: now, Valid: true}
+			fmt.Printf("webhook delivery %v failed for good: %v\n", shortID(delivery.ID), outcome.Err)
+		}
+
+		if err := s.DB.MarkWebhookDeliveryFailed(context.Background(), database.MarkWebhookDeliveryFailedParams{
+			ID:            delivery.ID,
+			LastError:     sql.NullString{String: outcome.Err.Error(), Valid: true},
+			NextAttemptAt: outcome.NextAttemptAt,
+			FailedAt:      failedAt,
+			UpdatedAt:     now,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
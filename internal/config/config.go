@@ -10,6 +10,19 @@ const cfgFile = ".gatorconfig.json"
 type Config struct {
 	DBURL           string `json:"db_url"`
 	CurrentUsername string `json:"current_user_name"`
+	SessionToken    string `json:"session_token,omitempty"`
+	DownloadDir     string `json:"download_dir,omitempty"`
+	SMTP            SMTP   `json:"smtp,omitempty"`
+}
+
+// SMTP is the mail server digests are sent through. Port defaults to 587
+// and Username may be left empty for servers that don't need auth.
+type SMTP struct {
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	From     string `json:"from,omitempty"`
 }
 
 func InitializeConfig() (*Config, error) {
@@ -20,9 +33,29 @@ func InitializeConfig() (*Config, error) {
 	return &cfg, nil
 }
 
-func (cfg *Config) SetUser(username string) error {
+// SetSession records the logged in user and the token that proves it.
+// CurrentUsername is only informational, commands trust the token.
+func (cfg *Config) SetSession(username, token string) error {
 	cfg.CurrentUsername = username
+	cfg.SessionToken = token
+	return cfg.write()
+}
 
+// EpisodeDir returns the directory podcast episodes are downloaded to,
+// defaulting to ~/gator-downloads
+func (cfg *Config) EpisodeDir() (string, error) {
+	if cfg.DownloadDir != "" {
+		return cfg.DownloadDir, nil
+	}
+
+	homePath, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return homePath + "/gator-downloads", nil
+}
+
+func (cfg *Config) write() error {
 	cfgJSON, err := json.Marshal(cfg)
 	if err != nil {
 		return err
@@ -33,7 +66,11 @@ func (cfg *Config) SetUser(username string) error {
 		return err
 	}
 
-	if err := os.WriteFile(cfgPath, cfgJSON, 0644); err != nil {
+	// The session token is a secret, so keep the file private
+	if err := os.WriteFile(cfgPath, cfgJSON, 0600); err != nil {
+		return err
+	}
+	if err := os.Chmod(cfgPath, 0600); err != nil {
 		return err
 	}
 
@@ -0,0 +1,189 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: alert_rules.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createAlertRule = `-- name: CreateAlertRule :one
+INSERT INTO alert_rules (id, created_at, updated_at, user_id, feed_id, field, match_type, pattern, action, target)
+VALUES ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10 )
+RETURNING id, created_at, updated_at, user_id, feed_id, field, match_type, pattern, action, target
+`
+
+type CreateAlertRuleParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.NullUUID
+	Field     string
+	MatchType string
+	Pattern   string
+	Action    string
+	Target    string
+}
+
+func (q *Queries) CreateAlertRule(ctx context.Context, arg CreateAlertRuleParams) (AlertRule, error) {
+	row := q.db.QueryRowContext(ctx, createAlertRule,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+		arg.Field,
+		arg.MatchType,
+		arg.Pattern,
+		arg.Action,
+		arg.Target,
+	)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Field,
+		&i.MatchType,
+		&i.Pattern,
+		&i.Action,
+		&i.Target,
+	)
+	return i, err
+}
+
+const deleteAlertRule = `-- name: DeleteAlertRule :exec
+DELETE FROM alert_rules
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteAlertRuleParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteAlertRule(ctx context.Context, arg DeleteAlertRuleParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAlertRule, arg.ID, arg.UserID)
+	return err
+}
+
+const getAlertRulesForFeed = `-- name: GetAlertRulesForFeed :many
+SELECT alert_rules.id, alert_rules.created_at, alert_rules.updated_at, alert_rules.user_id, alert_rules.feed_id, alert_rules.field, alert_rules.match_type, alert_rules.pattern, alert_rules.action, alert_rules.target
+FROM alert_rules
+JOIN feed_follows ON feed_follows.user_id = alert_rules.user_id AND feed_follows.feed_id = $1
+JOIN users ON users.id = alert_rules.user_id
+WHERE (alert_rules.feed_id IS NULL OR alert_rules.feed_id = $1)
+  AND (
+    alert_rules.action NOT IN ('command', 'file')
+    OR alert_rules.user_id = $2
+    OR users.role = 'admin'
+  )
+ORDER BY alert_rules.created_at
+`
+
+type GetAlertRulesForFeedParams struct {
+	FeedID   uuid.NullUUID
+	RunnerID uuid.UUID
+}
+
+// Command and file actions run as whoever runs agg, so they only come
+// from that user's own rules or from admins
+func (q *Queries) GetAlertRulesForFeed(ctx context.Context, arg GetAlertRulesForFeedParams) ([]AlertRule, error) {
+	rows, err := q.db.QueryContext(ctx, getAlertRulesForFeed, arg.FeedID, arg.RunnerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AlertRule
+	for rows.Next() {
+		var i AlertRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.Field,
+			&i.MatchType,
+			&i.Pattern,
+			&i.Action,
+			&i.Target,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAlertRulesForUser = `-- name: GetAlertRulesForUser :many
+SELECT alert_rules.id, alert_rules.created_at, alert_rules.updated_at, alert_rules.user_id, alert_rules.feed_id, alert_rules.field, alert_rules.match_type, alert_rules.pattern, alert_rules.action, alert_rules.target, feeds.name AS feed_name
+FROM alert_rules
+LEFT JOIN feeds ON feeds.id = alert_rules.feed_id
+WHERE alert_rules.user_id = $1
+ORDER BY alert_rules.created_at
+`
+
+type GetAlertRulesForUserRow struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.NullUUID
+	Field     string
+	MatchType string
+	Pattern   string
+	Action    string
+	Target    string
+	FeedName  sql.NullString
+}
+
+func (q *Queries) GetAlertRulesForUser(ctx context.Context, userID uuid.UUID) ([]GetAlertRulesForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAlertRulesForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAlertRulesForUserRow
+	for rows.Next() {
+		var i GetAlertRulesForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.Field,
+			&i.MatchType,
+			&i.Pattern,
+			&i.Action,
+			&i.Target,
+			&i.FeedName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
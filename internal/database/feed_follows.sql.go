@@ -7,11 +7,24 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const countFollowersForFeed = `-- name: CountFollowersForFeed :one
+SELECT count(*) FROM feed_follows
+WHERE feed_id = $1
+`
+
+func (q *Queries) CountFollowersForFeed(ctx context.Context, feedID uuid.NullUUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFollowersForFeed, feedID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFeedFollow = `-- name: CreateFeedFollow :one
 WITH inserted_feed_follow AS (
   INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
@@ -22,9 +35,9 @@ WITH inserted_feed_follow AS (
    $4,
    $5
   )
-  RETURNING id, created_at, updated_at, user_id, feed_id
+  RETURNING id, created_at, updated_at, user_id, feed_id, keep_episodes, folder
 )
-SELECT inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, feeds.name AS feed_name, users.name AS user_name
+SELECT inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, inserted_feed_follow.keep_episodes, inserted_feed_follow.folder, feeds.name AS feed_name, users.name AS user_name
 FROM inserted_feed_follow
 INNER JOIN feeds ON feeds.id = inserted_feed_follow.feed_id
 INNER JOIN users ON users.id = inserted_feed_follow.user_id
@@ -39,13 +52,15 @@ type CreateFeedFollowParams struct {
 }
 
 type CreateFeedFollowRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.NullUUID
-	FeedID    uuid.NullUUID
-	FeedName  string
-	UserName  string
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.NullUUID
+	FeedID       uuid.NullUUID
+	KeepEpisodes sql.NullInt32
+	Folder       sql.NullString
+	FeedName     string
+	UserName     string
 }
 
 func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error) {
@@ -63,6 +78,8 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.KeepEpisodes,
+		&i.Folder,
 		&i.FeedName,
 		&i.UserName,
 	)
@@ -72,7 +89,7 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 const deleteFeedFollow = `-- name: DeleteFeedFollow :one
 DELETE FROM feed_follows
 WHERE user_id = $1 AND feed_id = $2
-RETURNING id, created_at, updated_at, user_id, feed_id
+RETURNING id, created_at, updated_at, user_id, feed_id, keep_episodes, folder
 `
 
 type DeleteFeedFollowParams struct {
@@ -89,26 +106,90 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.KeepEpisodes,
+		&i.Folder,
 	)
 	return i, err
 }
 
+const getAllFeedFollows = `-- name: GetAllFeedFollows :many
+SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.keep_episodes, feed_follows.folder, feeds.name AS feed_name, feeds.url AS feed_url, feeds.site_url AS feed_site_url, users.name AS user_name
+FROM feed_follows
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+INNER JOIN users ON users.id = feed_follows.user_id
+ORDER BY users.name, feeds.name
+`
+
+type GetAllFeedFollowsRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.NullUUID
+	FeedID       uuid.NullUUID
+	KeepEpisodes sql.NullInt32
+	Folder       sql.NullString
+	FeedName     string
+	FeedUrl      string
+	FeedSiteUrl  sql.NullString
+	UserName     string
+}
+
+func (q *Queries) GetAllFeedFollows(ctx context.Context) ([]GetAllFeedFollowsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAllFeedFollows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAllFeedFollowsRow
+	for rows.Next() {
+		var i GetAllFeedFollowsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.KeepEpisodes,
+			&i.Folder,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.FeedSiteUrl,
+			&i.UserName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
-SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feeds.name AS feed_name, users.name AS user_name 
+SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.keep_episodes, feed_follows.folder, feeds.name AS feed_name, feeds.url AS feed_url, feeds.site_url AS feed_site_url, users.name AS user_name 
 FROM feed_follows
 INNER JOIN feeds ON feeds.id = feed_follows.feed_id
 INNER JOIN users ON users.id = feed_follows.user_id
 WHERE feed_follows.user_id = $1
+ORDER BY feeds.name
 `
 
 type GetFeedFollowsForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.NullUUID
-	FeedID    uuid.NullUUID
-	FeedName  string
-	UserName  string
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.NullUUID
+	FeedID       uuid.NullUUID
+	KeepEpisodes sql.NullInt32
+	Folder       sql.NullString
+	FeedName     string
+	FeedUrl      string
+	FeedSiteUrl  sql.NullString
+	UserName     string
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.NullUUID) ([]GetFeedFollowsForUserRow, error) {
@@ -126,7 +207,11 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.NullUUI
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.KeepEpisodes,
+			&i.Folder,
 			&i.FeedName,
+			&i.FeedUrl,
+			&i.FeedSiteUrl,
 			&i.UserName,
 		); err != nil {
 			return nil, err
@@ -141,3 +226,131 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.NullUUI
 	}
 	return items, nil
 }
+
+const getFeedFollowsPageForUser = `-- name: GetFeedFollowsPageForUser :many
+SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.keep_episodes, feed_follows.folder, feeds.name AS feed_name, feeds.url AS feed_url
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name
+LIMIT $2 OFFSET $3
+`
+
+type GetFeedFollowsPageForUserParams struct {
+	UserID uuid.NullUUID
+	Limit  int32
+	Offset int32
+}
+
+type GetFeedFollowsPageForUserRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.NullUUID
+	FeedID       uuid.NullUUID
+	KeepEpisodes sql.NullInt32
+	Folder       sql.NullString
+	FeedName     string
+	FeedUrl      string
+}
+
+func (q *Queries) GetFeedFollowsPageForUser(ctx context.Context, arg GetFeedFollowsPageForUserParams) ([]GetFeedFollowsPageForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedFollowsPageForUser, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedFollowsPageForUserRow
+	for rows.Next() {
+		var i GetFeedFollowsPageForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.KeepEpisodes,
+			&i.Folder,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setFeedFollowFolder = `-- name: SetFeedFollowFolder :one
+UPDATE feed_follows
+SET folder = $3, updated_at = $4
+WHERE user_id = $1 AND feed_id = $2
+RETURNING id, created_at, updated_at, user_id, feed_id, keep_episodes, folder
+`
+
+type SetFeedFollowFolderParams struct {
+	UserID    uuid.NullUUID
+	FeedID    uuid.NullUUID
+	Folder    sql.NullString
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetFeedFollowFolder(ctx context.Context, arg SetFeedFollowFolderParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, setFeedFollowFolder,
+		arg.UserID,
+		arg.FeedID,
+		arg.Folder,
+		arg.UpdatedAt,
+	)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.KeepEpisodes,
+		&i.Folder,
+	)
+	return i, err
+}
+
+const setFeedFollowKeepEpisodes = `-- name: SetFeedFollowKeepEpisodes :one
+UPDATE feed_follows
+SET keep_episodes = $3, updated_at = $4
+WHERE user_id = $1 AND feed_id = $2
+RETURNING id, created_at, updated_at, user_id, feed_id, keep_episodes, folder
+`
+
+type SetFeedFollowKeepEpisodesParams struct {
+	UserID       uuid.NullUUID
+	FeedID       uuid.NullUUID
+	KeepEpisodes sql.NullInt32
+	UpdatedAt    time.Time
+}
+
+func (q *Queries) SetFeedFollowKeepEpisodes(ctx context.Context, arg SetFeedFollowKeepEpisodesParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, setFeedFollowKeepEpisodes,
+		arg.UserID,
+		arg.FeedID,
+		arg.KeepEpisodes,
+		arg.UpdatedAt,
+	)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.KeepEpisodes,
+		&i.Folder,
+	)
+	return i, err
+}
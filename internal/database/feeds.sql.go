@@ -13,25 +13,55 @@ import (
 	"github.com/google/uuid"
 )
 
+const clearFollowedFeedsOrphanedAt = `-- name: ClearFollowedFeedsOrphanedAt :exec
+UPDATE feeds
+SET orphaned_at = NULL
+WHERE orphaned_at IS NOT NULL
+  AND EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)
+`
+
+func (q *Queries) ClearFollowedFeedsOrphanedAt(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, clearFollowedFeedsOrphanedAt)
+	return err
+}
+
+const countFeedsForUser = `-- name: CountFeedsForUser :one
+SELECT count(*) FROM feeds
+WHERE user_id = $1
+`
+
+func (q *Queries) CountFeedsForUser(ctx context.Context, userID uuid.NullUUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedsForUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds ( id, created_at, updated_at, name, url, user_id  ) 
+INSERT INTO feeds ( id, created_at, updated_at, name, url, user_id, site_url, description, image_url  ) 
 VALUES ( 
   $1,
   $2,
   $3,
   $4,
   $5,
-  $6
-) RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+  $6,
+  $7,
+  $8,
+  $9
+) RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, site_url, description, image_url, orphaned_at, retention_max_age_hours, retention_max_count, fever_id
 `
 
 type CreateFeedParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
-	Url       string
-	UserID    uuid.NullUUID
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Name        string
+	Url         string
+	UserID      uuid.NullUUID
+	SiteUrl     sql.NullString
+	Description sql.NullString
+	ImageUrl    sql.NullString
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -42,6 +72,9 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		arg.Name,
 		arg.Url,
 		arg.UserID,
+		arg.SiteUrl,
+		arg.Description,
+		arg.ImageUrl,
 	)
 	var i Feed
 	err := row.Scan(
@@ -52,12 +85,56 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.OrphanedAt,
+		&i.RetentionMaxAgeHours,
+		&i.RetentionMaxCount,
+		&i.FeverID,
+	)
+	return i, err
+}
+
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
+const getFeedByID = `-- name: GetFeedByID :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_url, description, image_url, orphaned_at, retention_max_age_hours, retention_max_count, fever_id FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) GetFeedByID(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByID, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.OrphanedAt,
+		&i.RetentionMaxAgeHours,
+		&i.RetentionMaxCount,
+		&i.FeverID,
 	)
 	return i, err
 }
 
 const getFeedByUrl = `-- name: GetFeedByUrl :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_url, description, image_url, orphaned_at, retention_max_age_hours, retention_max_count, fever_id FROM feeds
 WHERE url = $1
 `
 
@@ -72,12 +149,19 @@ func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.OrphanedAt,
+		&i.RetentionMaxAgeHours,
+		&i.RetentionMaxCount,
+		&i.FeverID,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_url, description, image_url, orphaned_at, retention_max_age_hours, retention_max_count, fever_id FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -97,6 +181,83 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.SiteUrl,
+			&i.Description,
+			&i.ImageUrl,
+			&i.OrphanedAt,
+			&i.RetentionMaxAgeHours,
+			&i.RetentionMaxCount,
+			&i.FeverID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsPage = `-- name: GetFeedsPage :many
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.site_url, feeds.description, feeds.image_url, feeds.orphaned_at, feeds.retention_max_age_hours, feeds.retention_max_count, feeds.fever_id, users.name AS user_name
+FROM feeds
+LEFT JOIN users ON users.id = feeds.user_id
+ORDER BY feeds.name
+LIMIT $1 OFFSET $2
+`
+
+type GetFeedsPageParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type GetFeedsPageRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Name                 string
+	Url                  string
+	UserID               uuid.NullUUID
+	LastFetchedAt        sql.NullTime
+	SiteUrl              sql.NullString
+	Description          sql.NullString
+	ImageUrl             sql.NullString
+	OrphanedAt           sql.NullTime
+	RetentionMaxAgeHours sql.NullInt32
+	RetentionMaxCount    sql.NullInt32
+	FeverID              int64
+	UserName             sql.NullString
+}
+
+func (q *Queries) GetFeedsPage(ctx context.Context, arg GetFeedsPageParams) ([]GetFeedsPageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsPage, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedsPageRow
+	for rows.Next() {
+		var i GetFeedsPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.SiteUrl,
+			&i.Description,
+			&i.ImageUrl,
+			&i.OrphanedAt,
+			&i.RetentionMaxAgeHours,
+			&i.RetentionMaxCount,
+			&i.FeverID,
+			&i.UserName,
 		); err != nil {
 			return nil, err
 		}
@@ -112,7 +273,7 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 }
 
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.user_id, f.last_fetched_at 
+SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.user_id, f.last_fetched_at, f.site_url, f.description, f.image_url, f.orphaned_at, f.retention_max_age_hours, f.retention_max_count, f.fever_id 
 FROM feeds f
 JOIN feed_follows ff ON f.id = ff.feed_id
 WHERE ff.user_id = $1
@@ -131,15 +292,90 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context, userID uuid.NullUUID)
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.OrphanedAt,
+		&i.RetentionMaxAgeHours,
+		&i.RetentionMaxCount,
+		&i.FeverID,
 	)
 	return i, err
 }
 
+const getOrphanedFeeds = `-- name: GetOrphanedFeeds :many
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.site_url, feeds.description, feeds.image_url, feeds.orphaned_at, feeds.retention_max_age_hours, feeds.retention_max_count, feeds.fever_id,
+  (SELECT COUNT(*) FROM posts WHERE posts.feed_id = feeds.id) AS post_count,
+  (SELECT COALESCE(SUM(pg_column_size(posts.*)), 0) FROM posts WHERE posts.feed_id = feeds.id)::bigint AS post_bytes
+FROM feeds
+WHERE orphaned_at IS NOT NULL
+ORDER BY orphaned_at, name
+`
+
+type GetOrphanedFeedsRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Name                 string
+	Url                  string
+	UserID               uuid.NullUUID
+	LastFetchedAt        sql.NullTime
+	SiteUrl              sql.NullString
+	Description          sql.NullString
+	ImageUrl             sql.NullString
+	OrphanedAt           sql.NullTime
+	RetentionMaxAgeHours sql.NullInt32
+	RetentionMaxCount    sql.NullInt32
+	FeverID              int64
+	PostCount            int64
+	PostBytes            int64
+}
+
+func (q *Queries) GetOrphanedFeeds(ctx context.Context) ([]GetOrphanedFeedsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrphanedFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrphanedFeedsRow
+	for rows.Next() {
+		var i GetOrphanedFeedsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.SiteUrl,
+			&i.Description,
+			&i.ImageUrl,
+			&i.OrphanedAt,
+			&i.RetentionMaxAgeHours,
+			&i.RetentionMaxCount,
+			&i.FeverID,
+			&i.PostCount,
+			&i.PostBytes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const markFeedFetched = `-- name: MarkFeedFetched :one
 UPDATE feeds
 SET last_fetched_at = $2, updated_at = $2
 WHERE id = $1
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, site_url, description, image_url, orphaned_at, retention_max_age_hours, retention_max_count, fever_id
 `
 
 type MarkFeedFetchedParams struct {
@@ -158,6 +394,156 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.OrphanedAt,
+		&i.RetentionMaxAgeHours,
+		&i.RetentionMaxCount,
+		&i.FeverID,
 	)
 	return i, err
 }
+
+const markOrphanedFeeds = `-- name: MarkOrphanedFeeds :exec
+UPDATE feeds
+SET orphaned_at = $1
+WHERE orphaned_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)
+`
+
+func (q *Queries) MarkOrphanedFeeds(ctx context.Context, orphanedAt sql.NullTime) error {
+	_, err := q.db.ExecContext(ctx, markOrphanedFeeds, orphanedAt)
+	return err
+}
+
+const renameFeed = `-- name: RenameFeed :one
+UPDATE feeds
+SET name = $2, updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, site_url, description, image_url, orphaned_at, retention_max_age_hours, retention_max_count, fever_id
+`
+
+type RenameFeedParams struct {
+	ID        uuid.UUID
+	Name      string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) RenameFeed(ctx context.Context, arg RenameFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, renameFeed, arg.ID, arg.Name, arg.UpdatedAt)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.OrphanedAt,
+		&i.RetentionMaxAgeHours,
+		&i.RetentionMaxCount,
+		&i.FeverID,
+	)
+	return i, err
+}
+
+const setFeedRetention = `-- name: SetFeedRetention :one
+UPDATE feeds
+SET retention_max_age_hours = $2, retention_max_count = $3, updated_at = $4
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, site_url, description, image_url, orphaned_at, retention_max_age_hours, retention_max_count, fever_id
+`
+
+type SetFeedRetentionParams struct {
+	ID                   uuid.UUID
+	RetentionMaxAgeHours sql.NullInt32
+	RetentionMaxCount    sql.NullInt32
+	UpdatedAt            time.Time
+}
+
+func (q *Queries) SetFeedRetention(ctx context.Context, arg SetFeedRetentionParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedRetention,
+		arg.ID,
+		arg.RetentionMaxAgeHours,
+		arg.RetentionMaxCount,
+		arg.UpdatedAt,
+	)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.OrphanedAt,
+		&i.RetentionMaxAgeHours,
+		&i.RetentionMaxCount,
+		&i.FeverID,
+	)
+	return i, err
+}
+
+const setFeedUrl = `-- name: SetFeedUrl :one
+UPDATE feeds
+SET url = $2, updated_at = $3, last_fetched_at = NULL
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, site_url, description, image_url, orphaned_at, retention_max_age_hours, retention_max_count, fever_id
+`
+
+type SetFeedUrlParams struct {
+	ID        uuid.UUID
+	Url       string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetFeedUrl(ctx context.Context, arg SetFeedUrlParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedUrl, arg.ID, arg.Url, arg.UpdatedAt)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.OrphanedAt,
+		&i.RetentionMaxAgeHours,
+		&i.RetentionMaxCount,
+		&i.FeverID,
+	)
+	return i, err
+}
+
+const transferFeeds = `-- name: TransferFeeds :execrows
+UPDATE feeds
+SET user_id = $1, updated_at = $2
+WHERE user_id = $3
+`
+
+type TransferFeedsParams struct {
+	ToUserID   uuid.NullUUID
+	UpdatedAt  time.Time
+	FromUserID uuid.NullUUID
+}
+
+func (q *Queries) TransferFeeds(ctx context.Context, arg TransferFeedsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, transferFeeds, arg.ToUserID, arg.UpdatedAt, arg.FromUserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,459 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: fever.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const countPostsForUser = `-- name: CountPostsForUser :one
+SELECT COUNT(*) FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+`
+
+func (q *Queries) CountPostsForUser(ctx context.Context, userID uuid.NullUUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsForUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getFeverFeedsForUser = `-- name: GetFeverFeedsForUser :many
+SELECT feeds.fever_id, feeds.name, feeds.url, feeds.site_url, feeds.last_fetched_at, feed_follows.folder
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name
+`
+
+type GetFeverFeedsForUserRow struct {
+	FeverID       int64
+	Name          string
+	Url           string
+	SiteUrl       sql.NullString
+	LastFetchedAt sql.NullTime
+	Folder        sql.NullString
+}
+
+func (q *Queries) GetFeverFeedsForUser(ctx context.Context, userID uuid.NullUUID) ([]GetFeverFeedsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeverFeedsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeverFeedsForUserRow
+	for rows.Next() {
+		var i GetFeverFeedsForUserRow
+		if err := rows.Scan(
+			&i.FeverID,
+			&i.Name,
+			&i.Url,
+			&i.SiteUrl,
+			&i.LastFetchedAt,
+			&i.Folder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeverItemsBefore = `-- name: GetFeverItemsBefore :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.comments_url, posts.duration_seconds, posts.episode, posts.season, posts.image_url, posts.fever_id,
+  feeds.fever_id AS feed_fever_id,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND posts.fever_id < $2
+ORDER BY posts.fever_id DESC
+LIMIT $3
+`
+
+type GetFeverItemsBeforeParams struct {
+	UserID   uuid.NullUUID
+	MaxID    int64
+	RowLimit int32
+}
+
+type GetFeverItemsBeforeRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           sql.NullString
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.NullUUID
+	Content         sql.NullString
+	Author          sql.NullString
+	Categories      []string
+	CommentsUrl     sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ImageUrl        sql.NullString
+	FeverID         int64
+	FeedFeverID     int64
+	Read            bool
+	Starred         bool
+}
+
+func (q *Queries) GetFeverItemsBefore(ctx context.Context, arg GetFeverItemsBeforeParams) ([]GetFeverItemsBeforeRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeverItemsBefore, arg.UserID, arg.MaxID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeverItemsBeforeRow
+	for rows.Next() {
+		var i GetFeverItemsBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.CommentsUrl,
+			&i.DurationSeconds,
+			&i.Episode,
+			&i.Season,
+			&i.ImageUrl,
+			&i.FeverID,
+			&i.FeedFeverID,
+			&i.Read,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeverItemsByIDs = `-- name: GetFeverItemsByIDs :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.comments_url, posts.duration_seconds, posts.episode, posts.season, posts.image_url, posts.fever_id,
+  feeds.fever_id AS feed_fever_id,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND posts.fever_id = ANY($2::bigint[])
+ORDER BY posts.fever_id
+`
+
+type GetFeverItemsByIDsParams struct {
+	UserID uuid.NullUUID
+	Ids    []int64
+}
+
+type GetFeverItemsByIDsRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           sql.NullString
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.NullUUID
+	Content         sql.NullString
+	Author          sql.NullString
+	Categories      []string
+	CommentsUrl     sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ImageUrl        sql.NullString
+	FeverID         int64
+	FeedFeverID     int64
+	Read            bool
+	Starred         bool
+}
+
+func (q *Queries) GetFeverItemsByIDs(ctx context.Context, arg GetFeverItemsByIDsParams) ([]GetFeverItemsByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeverItemsByIDs, arg.UserID, pq.Array(arg.Ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeverItemsByIDsRow
+	for rows.Next() {
+		var i GetFeverItemsByIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.CommentsUrl,
+			&i.DurationSeconds,
+			&i.Episode,
+			&i.Season,
+			&i.ImageUrl,
+			&i.FeverID,
+			&i.FeedFeverID,
+			&i.Read,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeverItemsSince = `-- name: GetFeverItemsSince :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.comments_url, posts.duration_seconds, posts.episode, posts.season, posts.image_url, posts.fever_id,
+  feeds.fever_id AS feed_fever_id,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND posts.fever_id > $2
+ORDER BY posts.fever_id
+LIMIT $3
+`
+
+type GetFeverItemsSinceParams struct {
+	UserID   uuid.NullUUID
+	SinceID  int64
+	RowLimit int32
+}
+
+type GetFeverItemsSinceRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           sql.NullString
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.NullUUID
+	Content         sql.NullString
+	Author          sql.NullString
+	Categories      []string
+	CommentsUrl     sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ImageUrl        sql.NullString
+	FeverID         int64
+	FeedFeverID     int64
+	Read            bool
+	Starred         bool
+}
+
+func (q *Queries) GetFeverItemsSince(ctx context.Context, arg GetFeverItemsSinceParams) ([]GetFeverItemsSinceRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeverItemsSince, arg.UserID, arg.SinceID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeverItemsSinceRow
+	for rows.Next() {
+		var i GetFeverItemsSinceRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.CommentsUrl,
+			&i.DurationSeconds,
+			&i.Episode,
+			&i.Season,
+			&i.ImageUrl,
+			&i.FeverID,
+			&i.FeedFeverID,
+			&i.Read,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostIDByFeverIDForUser = `-- name: GetPostIDByFeverIDForUser :one
+SELECT posts.id
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+  AND posts.fever_id = $2
+`
+
+type GetPostIDByFeverIDForUserParams struct {
+	UserID  uuid.NullUUID
+	FeverID int64
+}
+
+func (q *Queries) GetPostIDByFeverIDForUser(ctx context.Context, arg GetPostIDByFeverIDForUserParams) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, getPostIDByFeverIDForUser, arg.UserID, arg.FeverID)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getStarredFeverIDsForUser = `-- name: GetStarredFeverIDsForUser :many
+SELECT posts.fever_id
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND post_states.starred
+ORDER BY posts.fever_id
+`
+
+func (q *Queries) GetStarredFeverIDsForUser(ctx context.Context, userID uuid.NullUUID) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getStarredFeverIDsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var fever_id int64
+		if err := rows.Scan(&fever_id); err != nil {
+			return nil, err
+		}
+		items = append(items, fever_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnreadFeverIDsForUser = `-- name: GetUnreadFeverIDsForUser :many
+SELECT posts.fever_id
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND NOT COALESCE(post_states.read, false)
+ORDER BY posts.fever_id
+`
+
+func (q *Queries) GetUnreadFeverIDsForUser(ctx context.Context, userID uuid.NullUUID) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadFeverIDsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var fever_id int64
+		if err := rows.Scan(&fever_id); err != nil {
+			return nil, err
+		}
+		items = append(items, fever_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markPostsReadForUser = `-- name: MarkPostsReadForUser :execrows
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read)
+SELECT feed_follows.user_id, posts.id, $1, $1, true
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $2
+  AND posts.created_at < $3
+  AND ($4::bigint IS NULL OR feeds.fever_id = $4)
+  AND ($5::text IS NULL OR feed_follows.folder = $5)
+ON CONFLICT (user_id, post_id)
+DO UPDATE SET read = true, updated_at = EXCLUDED.updated_at
+`
+
+type MarkPostsReadForUserParams struct {
+	Now         time.Time
+	UserID      uuid.NullUUID
+	Before      time.Time
+	FeedFeverID sql.NullInt64
+	Folder      sql.NullString
+}
+
+func (q *Queries) MarkPostsReadForUser(ctx context.Context, arg MarkPostsReadForUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markPostsReadForUser,
+		arg.Now,
+		arg.UserID,
+		arg.Before,
+		arg.FeedFeverID,
+		arg.Folder,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
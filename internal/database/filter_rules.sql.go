@@ -0,0 +1,218 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: filter_rules.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createFilterRule = `-- name: CreateFilterRule :one
+INSERT INTO filter_rules (id, created_at, updated_at, user_id, feed_id, action, field, match_type, pattern, at_ingest)
+VALUES ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10 )
+RETURNING id, created_at, updated_at, user_id, feed_id, action, field, match_type, pattern, at_ingest
+`
+
+type CreateFilterRuleParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.NullUUID
+	Action    string
+	Field     string
+	MatchType string
+	Pattern   string
+	AtIngest  bool
+}
+
+func (q *Queries) CreateFilterRule(ctx context.Context, arg CreateFilterRuleParams) (FilterRule, error) {
+	row := q.db.QueryRowContext(ctx, createFilterRule,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+		arg.Action,
+		arg.Field,
+		arg.MatchType,
+		arg.Pattern,
+		arg.AtIngest,
+	)
+	var i FilterRule
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Action,
+		&i.Field,
+		&i.MatchType,
+		&i.Pattern,
+		&i.AtIngest,
+	)
+	return i, err
+}
+
+const deleteFilterRule = `-- name: DeleteFilterRule :exec
+DELETE FROM filter_rules
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteFilterRuleParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteFilterRule(ctx context.Context, arg DeleteFilterRuleParams) error {
+	_, err := q.db.ExecContext(ctx, deleteFilterRule, arg.ID, arg.UserID)
+	return err
+}
+
+const getFilterRulesForUser = `-- name: GetFilterRulesForUser :many
+SELECT filter_rules.id, filter_rules.created_at, filter_rules.updated_at, filter_rules.user_id, filter_rules.feed_id, filter_rules.action, filter_rules.field, filter_rules.match_type, filter_rules.pattern, filter_rules.at_ingest, feeds.name AS feed_name
+FROM filter_rules
+LEFT JOIN feeds ON feeds.id = filter_rules.feed_id
+WHERE filter_rules.user_id = $1
+ORDER BY filter_rules.created_at
+`
+
+type GetFilterRulesForUserRow struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.NullUUID
+	Action    string
+	Field     string
+	MatchType string
+	Pattern   string
+	AtIngest  bool
+	FeedName  sql.NullString
+}
+
+func (q *Queries) GetFilterRulesForUser(ctx context.Context, userID uuid.UUID) ([]GetFilterRulesForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFilterRulesForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFilterRulesForUserRow
+	for rows.Next() {
+		var i GetFilterRulesForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.Action,
+			&i.Field,
+			&i.MatchType,
+			&i.Pattern,
+			&i.AtIngest,
+			&i.FeedName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFilterRulesToApply = `-- name: GetFilterRulesToApply :many
+SELECT id, created_at, updated_at, user_id, feed_id, action, field, match_type, pattern, at_ingest FROM filter_rules
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+// The user's rules without the feed names GetFilterRulesForUser lists
+func (q *Queries) GetFilterRulesToApply(ctx context.Context, userID uuid.UUID) ([]FilterRule, error) {
+	rows, err := q.db.QueryContext(ctx, getFilterRulesToApply, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FilterRule
+	for rows.Next() {
+		var i FilterRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.Action,
+			&i.Field,
+			&i.MatchType,
+			&i.Pattern,
+			&i.AtIngest,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getIngestFilterRules = `-- name: GetIngestFilterRules :many
+SELECT filter_rules.id, filter_rules.created_at, filter_rules.updated_at, filter_rules.user_id, filter_rules.feed_id, filter_rules.action, filter_rules.field, filter_rules.match_type, filter_rules.pattern, filter_rules.at_ingest
+FROM filter_rules
+JOIN users ON users.id = filter_rules.user_id
+WHERE filter_rules.at_ingest AND users.role = 'admin'
+ORDER BY filter_rules.created_at
+`
+
+// Ingest rules drop posts for everyone, so only admins' rules count
+func (q *Queries) GetIngestFilterRules(ctx context.Context) ([]FilterRule, error) {
+	rows, err := q.db.QueryContext(ctx, getIngestFilterRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FilterRule
+	for rows.Next() {
+		var i FilterRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.Action,
+			&i.Field,
+			&i.MatchType,
+			&i.Pattern,
+			&i.AtIngest,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
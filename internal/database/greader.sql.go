@@ -0,0 +1,140 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: greader.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const getReaderItems = `-- name: GetReaderItems :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.comments_url, posts.duration_seconds, posts.episode, posts.season, posts.image_url, posts.fever_id,
+  feeds.fever_id AS feed_fever_id,
+  feeds.name AS feed_name,
+  feeds.site_url AS feed_site_url,
+  feed_follows.folder,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND ($2::bigint[] IS NULL OR posts.fever_id = ANY($2::bigint[]))
+  AND ($3::bigint IS NULL OR feeds.fever_id = $3)
+  AND ($4::text IS NULL OR feed_follows.folder = $4)
+  AND (NOT $5::boolean OR COALESCE(post_states.starred, false))
+  AND (NOT $6::boolean OR COALESCE(post_states.read, false))
+  AND (NOT $7::boolean OR NOT COALESCE(post_states.read, false))
+  AND ($8::timestamp IS NULL OR posts.created_at > $8)
+ORDER BY
+  CASE WHEN $9::boolean THEN posts.fever_id END ASC,
+  posts.fever_id DESC
+LIMIT $11 OFFSET $10
+`
+
+type GetReaderItemsParams struct {
+	UserID      uuid.NullUUID
+	Ids         []int64
+	FeedFeverID sql.NullInt64
+	Folder      sql.NullString
+	StarredOnly bool
+	ReadOnly    bool
+	UnreadOnly  bool
+	NewerThan   sql.NullTime
+	OldestFirst bool
+	RowOffset   int32
+	RowLimit    int32
+}
+
+type GetReaderItemsRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           sql.NullString
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.NullUUID
+	Content         sql.NullString
+	Author          sql.NullString
+	Categories      []string
+	CommentsUrl     sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ImageUrl        sql.NullString
+	FeverID         int64
+	FeedFeverID     int64
+	FeedName        string
+	FeedSiteUrl     sql.NullString
+	Folder          sql.NullString
+	Read            bool
+	Starred         bool
+}
+
+func (q *Queries) GetReaderItems(ctx context.Context, arg GetReaderItemsParams) ([]GetReaderItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReaderItems,
+		arg.UserID,
+		pq.Array(arg.Ids),
+		arg.FeedFeverID,
+		arg.Folder,
+		arg.StarredOnly,
+		arg.ReadOnly,
+		arg.UnreadOnly,
+		arg.NewerThan,
+		arg.OldestFirst,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReaderItemsRow
+	for rows.Next() {
+		var i GetReaderItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.CommentsUrl,
+			&i.DurationSeconds,
+			&i.Episode,
+			&i.Season,
+			&i.ImageUrl,
+			&i.FeverID,
+			&i.FeedFeverID,
+			&i.FeedName,
+			&i.FeedSiteUrl,
+			&i.Folder,
+			&i.Read,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -11,38 +11,167 @@ import (
 	"github.com/google/uuid"
 )
 
+type AlertRule struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.NullUUID
+	Field     string
+	MatchType string
+	Pattern   string
+	Action    string
+	Target    string
+}
+
+type ApiKey struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UserID     uuid.UUID
+	Name       string
+	KeyHash    string
+	LastUsedAt sql.NullTime
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.NullUUID
-	LastFetchedAt sql.NullTime
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Name                 string
+	Url                  string
+	UserID               uuid.NullUUID
+	LastFetchedAt        sql.NullTime
+	SiteUrl              sql.NullString
+	Description          sql.NullString
+	ImageUrl             sql.NullString
+	OrphanedAt           sql.NullTime
+	RetentionMaxAgeHours sql.NullInt32
+	RetentionMaxCount    sql.NullInt32
+	FeverID              int64
 }
 
 type FeedFollow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.NullUUID
+	FeedID       uuid.NullUUID
+	KeepEpisodes sql.NullInt32
+	Folder       sql.NullString
+}
+
+type FilterRule struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	UserID    uuid.NullUUID
+	UserID    uuid.UUID
 	FeedID    uuid.NullUUID
+	Action    string
+	Field     string
+	MatchType string
+	Pattern   string
+	AtIngest  bool
 }
 
 type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           sql.NullString
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.NullUUID
+	Content         sql.NullString
+	Author          sql.NullString
+	Categories      []string
+	CommentsUrl     sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ImageUrl        sql.NullString
+	FeverID         int64
+}
+
+type PostEnclosure struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	PostID    uuid.UUID
+	Url       string
+	Type      sql.NullString
+	Length    sql.NullInt64
+}
+
+type PostState struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Read      bool
+	Starred   bool
+}
+
+type PruneRun struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	RemovedPosts int32
+}
+
+type PrunedPost struct {
+	Url      string
+	FeedID   uuid.UUID
+	PrunedAt time.Time
+}
+
+type RetentionDefault struct {
+	ID          bool
+	MaxAgeHours sql.NullInt32
+	MaxCount    sql.NullInt32
 	UpdatedAt   time.Time
-	Title       sql.NullString
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.NullUUID
+}
+
+type Session struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UserID    uuid.UUID
+	TokenHash string
 }
 
 type User struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string
+	Email        sql.NullString
+	LastDigestAt sql.NullTime
+	FeverApiKey  sql.NullString
+	PasswordHash sql.NullString
+	Role         string
+}
+
+type Webhook struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Name      string
+	UserID    uuid.UUID
+	FeedID    uuid.NullUUID
+	Url       string
+	Secret    string
+	Global    bool
+}
+
+type WebhookDelivery struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	WebhookID     uuid.UUID
+	PostID        uuid.UUID
+	Payload       string
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     sql.NullString
+	DeliveredAt   sql.NullTime
+	FailedAt      sql.NullTime
 }
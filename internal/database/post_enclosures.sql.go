@@ -0,0 +1,150 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: post_enclosures.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createPostEnclosure = `-- name: CreatePostEnclosure :one
+INSERT INTO post_enclosures (id, created_at, updated_at, post_id, url, type, length)
+VALUES ( $1, $2, $3, $4, $5, $6, $7 )
+RETURNING id, created_at, updated_at, post_id, url, type, length
+`
+
+type CreatePostEnclosureParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	PostID    uuid.UUID
+	Url       string
+	Type      sql.NullString
+	Length    sql.NullInt64
+}
+
+func (q *Queries) CreatePostEnclosure(ctx context.Context, arg CreatePostEnclosureParams) (PostEnclosure, error) {
+	row := q.db.QueryRowContext(ctx, createPostEnclosure,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.PostID,
+		arg.Url,
+		arg.Type,
+		arg.Length,
+	)
+	var i PostEnclosure
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PostID,
+		&i.Url,
+		&i.Type,
+		&i.Length,
+	)
+	return i, err
+}
+
+const getEnclosuresForPost = `-- name: GetEnclosuresForPost :many
+SELECT id, created_at, updated_at, post_id, url, type, length FROM post_enclosures
+WHERE post_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetEnclosuresForPost(ctx context.Context, postID uuid.UUID) ([]PostEnclosure, error) {
+	rows, err := q.db.QueryContext(ctx, getEnclosuresForPost, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostEnclosure
+	for rows.Next() {
+		var i PostEnclosure
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PostID,
+			&i.Url,
+			&i.Type,
+			&i.Length,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEpisodesForFeed = `-- name: GetEpisodesForFeed :many
+SELECT post_enclosures.id, post_enclosures.created_at, post_enclosures.updated_at, post_enclosures.post_id, post_enclosures.url, post_enclosures.type, post_enclosures.length, posts.title AS post_title, posts.published_at AS post_published_at
+FROM post_enclosures
+JOIN posts ON posts.id = post_enclosures.post_id
+WHERE posts.feed_id = $1
+  AND (post_enclosures.type IS NULL OR post_enclosures.type LIKE 'audio/%' OR post_enclosures.type LIKE 'video/%')
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT $2
+`
+
+type GetEpisodesForFeedParams struct {
+	FeedID uuid.NullUUID
+	Limit  int32
+}
+
+type GetEpisodesForFeedRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	PostID          uuid.UUID
+	Url             string
+	Type            sql.NullString
+	Length          sql.NullInt64
+	PostTitle       sql.NullString
+	PostPublishedAt sql.NullTime
+}
+
+func (q *Queries) GetEpisodesForFeed(ctx context.Context, arg GetEpisodesForFeedParams) ([]GetEpisodesForFeedRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEpisodesForFeed, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEpisodesForFeedRow
+	for rows.Next() {
+		var i GetEpisodesForFeedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PostID,
+			&i.Url,
+			&i.Type,
+			&i.Length,
+			&i.PostTitle,
+			&i.PostPublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
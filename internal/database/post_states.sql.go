@@ -0,0 +1,61 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: post_states.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const setPostRead = `-- name: SetPostRead :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read)
+VALUES ( $1, $2, $3, $3, $4 )
+ON CONFLICT (user_id, post_id)
+DO UPDATE SET read = EXCLUDED.read, updated_at = EXCLUDED.updated_at
+`
+
+type SetPostReadParams struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+	Read      bool
+}
+
+func (q *Queries) SetPostRead(ctx context.Context, arg SetPostReadParams) error {
+	_, err := q.db.ExecContext(ctx, setPostRead,
+		arg.UserID,
+		arg.PostID,
+		arg.CreatedAt,
+		arg.Read,
+	)
+	return err
+}
+
+const setPostStarred = `-- name: SetPostStarred :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, starred)
+VALUES ( $1, $2, $3, $3, $4 )
+ON CONFLICT (user_id, post_id)
+DO UPDATE SET starred = EXCLUDED.starred, updated_at = EXCLUDED.updated_at
+`
+
+type SetPostStarredParams struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+	Starred   bool
+}
+
+func (q *Queries) SetPostStarred(ctx context.Context, arg SetPostStarredParams) error {
+	_, err := q.db.ExecContext(ctx, setPostStarred,
+		arg.UserID,
+		arg.PostID,
+		arg.CreatedAt,
+		arg.Starred,
+	)
+	return err
+}
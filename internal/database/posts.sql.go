@@ -11,8 +11,21 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countPostsForFeed = `-- name: CountPostsForFeed :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = $1
+`
+
+func (q *Queries) CountPostsForFeed(ctx context.Context, feedID uuid.NullUUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsForFeed, feedID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPost = `-- name: CreatePost :one
 INSERT INTO posts (
   id,
@@ -22,20 +35,36 @@ INSERT INTO posts (
   url,
   description,
   published_at,
-  feed_id
-) VALUES ( $1, $2, $3, $4, $5, $6, $7, $8 )
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+  feed_id,
+  content,
+  author,
+  categories,
+  comments_url,
+  duration_seconds,
+  episode,
+  season,
+  image_url
+) VALUES ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16 )
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, content, author, categories, comments_url, duration_seconds, episode, season, image_url, fever_id
 `
 
 type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       sql.NullString
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.NullUUID
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           sql.NullString
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.NullUUID
+	Content         sql.NullString
+	Author          sql.NullString
+	Categories      []string
+	CommentsUrl     sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ImageUrl        sql.NullString
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -48,6 +77,14 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Content,
+		arg.Author,
+		pq.Array(arg.Categories),
+		arg.CommentsUrl,
+		arg.DurationSeconds,
+		arg.Episode,
+		arg.Season,
+		arg.ImageUrl,
 	)
 	var i Post
 	err := row.Scan(
@@ -59,15 +96,93 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Content,
+		&i.Author,
+		pq.Array(&i.Categories),
+		&i.CommentsUrl,
+		&i.DurationSeconds,
+		&i.Episode,
+		&i.Season,
+		&i.ImageUrl,
+		&i.FeverID,
+	)
+	return i, err
+}
+
+const getPostWithStateForUser = `-- name: GetPostWithStateForUser :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.comments_url, posts.duration_seconds, posts.episode, posts.season, posts.image_url, posts.fever_id,
+  feeds.name AS feed_name,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND posts.id = $2
+`
+
+type GetPostWithStateForUserParams struct {
+	UserID uuid.NullUUID
+	PostID uuid.UUID
+}
+
+type GetPostWithStateForUserRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           sql.NullString
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.NullUUID
+	Content         sql.NullString
+	Author          sql.NullString
+	Categories      []string
+	CommentsUrl     sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ImageUrl        sql.NullString
+	FeverID         int64
+	FeedName        string
+	Read            bool
+	Starred         bool
+}
+
+func (q *Queries) GetPostWithStateForUser(ctx context.Context, arg GetPostWithStateForUserParams) (GetPostWithStateForUserRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostWithStateForUser, arg.UserID, arg.PostID)
+	var i GetPostWithStateForUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Content,
+		&i.Author,
+		pq.Array(&i.Categories),
+		&i.CommentsUrl,
+		&i.DurationSeconds,
+		&i.Episode,
+		&i.Season,
+		&i.ImageUrl,
+		&i.FeverID,
+		&i.FeedName,
+		&i.Read,
+		&i.Starred,
 	)
 	return i, err
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id FROM posts
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.comments_url, posts.duration_seconds, posts.episode, posts.season, posts.image_url, posts.fever_id FROM posts
 JOIN feed_follows on posts.feed_id = feed_follows.feed_id
 WHERE feed_follows.user_id = $1
-ORDER BY published_at DESC
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
 LIMIT $2
 `
 
@@ -94,6 +209,448 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Content,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.CommentsUrl,
+			&i.DurationSeconds,
+			&i.Episode,
+			&i.Season,
+			&i.ImageUrl,
+			&i.FeverID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserInFolder = `-- name: GetPostsForUserInFolder :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.comments_url, posts.duration_seconds, posts.episode, posts.season, posts.image_url, posts.fever_id FROM posts
+JOIN feed_follows on posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+  AND (feed_follows.folder = $3::text OR starts_with(feed_follows.folder, $3::text || '/'))
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT $2
+`
+
+type GetPostsForUserInFolderParams struct {
+	UserID uuid.NullUUID
+	Limit  int32
+	Folder string
+}
+
+func (q *Queries) GetPostsForUserInFolder(ctx context.Context, arg GetPostsForUserInFolderParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserInFolder, arg.UserID, arg.Limit, arg.Folder)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.CommentsUrl,
+			&i.DurationSeconds,
+			&i.Episode,
+			&i.Season,
+			&i.ImageUrl,
+			&i.FeverID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsPageForUser = `-- name: GetPostsPageForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.comments_url, posts.duration_seconds, posts.episode, posts.season, posts.image_url, posts.fever_id,
+  feeds.name AS feed_name,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND ($2::uuid IS NULL OR posts.feed_id = $2)
+  AND (NOT $3::boolean OR NOT COALESCE(post_states.read, false))
+  AND (NOT $4::boolean OR COALESCE(post_states.starred, false))
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC, posts.id
+LIMIT $6 OFFSET $5
+`
+
+type GetPostsPageForUserParams struct {
+	UserID      uuid.NullUUID
+	FeedID      uuid.NullUUID
+	UnreadOnly  bool
+	StarredOnly bool
+	RowOffset   int32
+	RowLimit    int32
+}
+
+type GetPostsPageForUserRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           sql.NullString
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.NullUUID
+	Content         sql.NullString
+	Author          sql.NullString
+	Categories      []string
+	CommentsUrl     sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ImageUrl        sql.NullString
+	FeverID         int64
+	FeedName        string
+	Read            bool
+	Starred         bool
+}
+
+func (q *Queries) GetPostsPageForUser(ctx context.Context, arg GetPostsPageForUserParams) ([]GetPostsPageForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsPageForUser,
+		arg.UserID,
+		arg.FeedID,
+		arg.UnreadOnly,
+		arg.StarredOnly,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsPageForUserRow
+	for rows.Next() {
+		var i GetPostsPageForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.CommentsUrl,
+			&i.DurationSeconds,
+			&i.Episode,
+			&i.Season,
+			&i.ImageUrl,
+			&i.FeverID,
+			&i.FeedName,
+			&i.Read,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsWithStateForUser = `-- name: GetPostsWithStateForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.comments_url, posts.duration_seconds, posts.episode, posts.season, posts.image_url, posts.fever_id,
+  feeds.name AS feed_name,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND ($2::uuid IS NULL OR posts.feed_id = $2)
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT $3
+`
+
+type GetPostsWithStateForUserParams struct {
+	UserID   uuid.NullUUID
+	FeedID   uuid.NullUUID
+	RowLimit int32
+}
+
+type GetPostsWithStateForUserRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           sql.NullString
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.NullUUID
+	Content         sql.NullString
+	Author          sql.NullString
+	Categories      []string
+	CommentsUrl     sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ImageUrl        sql.NullString
+	FeverID         int64
+	FeedName        string
+	Read            bool
+	Starred         bool
+}
+
+func (q *Queries) GetPostsWithStateForUser(ctx context.Context, arg GetPostsWithStateForUserParams) ([]GetPostsWithStateForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsWithStateForUser, arg.UserID, arg.FeedID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsWithStateForUserRow
+	for rows.Next() {
+		var i GetPostsWithStateForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.CommentsUrl,
+			&i.DurationSeconds,
+			&i.Episode,
+			&i.Season,
+			&i.ImageUrl,
+			&i.FeverID,
+			&i.FeedName,
+			&i.Read,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimelineForUser = `-- name: GetTimelineForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.comments_url, posts.duration_seconds, posts.episode, posts.season, posts.image_url, posts.fever_id, feeds.name AS feed_name, feeds.url AS feed_url
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND (
+    $2::text IS NULL
+    OR feed_follows.folder = $2::text
+    OR starts_with(feed_follows.folder, $2::text || '/')
+  )
+  AND (NOT $3::boolean OR COALESCE(post_states.starred, false))
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT $4
+`
+
+type GetTimelineForUserParams struct {
+	UserID      uuid.NullUUID
+	Folder      sql.NullString
+	StarredOnly bool
+	RowLimit    int32
+}
+
+type GetTimelineForUserRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           sql.NullString
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.NullUUID
+	Content         sql.NullString
+	Author          sql.NullString
+	Categories      []string
+	CommentsUrl     sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ImageUrl        sql.NullString
+	FeverID         int64
+	FeedName        string
+	FeedUrl         string
+}
+
+func (q *Queries) GetTimelineForUser(ctx context.Context, arg GetTimelineForUserParams) ([]GetTimelineForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTimelineForUser,
+		arg.UserID,
+		arg.Folder,
+		arg.StarredOnly,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTimelineForUserRow
+	for rows.Next() {
+		var i GetTimelineForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.CommentsUrl,
+			&i.DurationSeconds,
+			&i.Episode,
+			&i.Season,
+			&i.ImageUrl,
+			&i.FeverID,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnreadPostsForDigest = `-- name: GetUnreadPostsForDigest :many
+WITH oldest AS (
+  SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.comments_url, posts.duration_seconds, posts.episode, posts.season, posts.image_url, posts.fever_id, feeds.name AS feed_name
+  FROM posts
+  JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+  JOIN feeds ON feeds.id = posts.feed_id
+  LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+  WHERE feed_follows.user_id = $1
+    AND posts.created_at > $2
+    AND posts.created_at <= $3
+    AND NOT COALESCE(post_states.read, false)
+  ORDER BY posts.created_at
+  LIMIT $4
+)
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, content, author, categories, comments_url, duration_seconds, episode, season, image_url, fever_id, feed_name FROM oldest
+ORDER BY oldest.feed_name, COALESCE(oldest.published_at, oldest.created_at) DESC
+`
+
+type GetUnreadPostsForDigestParams struct {
+	UserID   uuid.NullUUID
+	Since    time.Time
+	Until    time.Time
+	RowLimit int32
+}
+
+type GetUnreadPostsForDigestRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           sql.NullString
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.NullUUID
+	Content         sql.NullString
+	Author          sql.NullString
+	Categories      []string
+	CommentsUrl     sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ImageUrl        sql.NullString
+	FeverID         int64
+	FeedName        string
+}
+
+// The limit keeps the oldest posts, so a capped digest can move its
+// watermark to the last post it includes
+func (q *Queries) GetUnreadPostsForDigest(ctx context.Context, arg GetUnreadPostsForDigestParams) ([]GetUnreadPostsForDigestRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadPostsForDigest,
+		arg.UserID,
+		arg.Since,
+		arg.Until,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUnreadPostsForDigestRow
+	for rows.Next() {
+		var i GetUnreadPostsForDigestRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.CommentsUrl,
+			&i.DurationSeconds,
+			&i.Episode,
+			&i.Season,
+			&i.ImageUrl,
+			&i.FeverID,
+			&i.FeedName,
 		); err != nil {
 			return nil, err
 		}
@@ -107,3 +664,152 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const getUnreadPostsForUser = `-- name: GetUnreadPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.comments_url, posts.duration_seconds, posts.episode, posts.season, posts.image_url, posts.fever_id,
+  feeds.name AS feed_name,
+  false AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND NOT COALESCE(post_states.read, false)
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT $2
+`
+
+type GetUnreadPostsForUserParams struct {
+	UserID   uuid.NullUUID
+	RowLimit int32
+}
+
+type GetUnreadPostsForUserRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           sql.NullString
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.NullUUID
+	Content         sql.NullString
+	Author          sql.NullString
+	Categories      []string
+	CommentsUrl     sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ImageUrl        sql.NullString
+	FeverID         int64
+	FeedName        string
+	Read            bool
+	Starred         bool
+}
+
+func (q *Queries) GetUnreadPostsForUser(ctx context.Context, arg GetUnreadPostsForUserParams) ([]GetUnreadPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadPostsForUser, arg.UserID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUnreadPostsForUserRow
+	for rows.Next() {
+		var i GetUnreadPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.CommentsUrl,
+			&i.DurationSeconds,
+			&i.Episode,
+			&i.Season,
+			&i.ImageUrl,
+			&i.FeverID,
+			&i.FeedName,
+			&i.Read,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isPostPruned = `-- name: IsPostPruned :one
+SELECT EXISTS (
+  SELECT 1 FROM pruned_posts
+  WHERE url = $1
+)
+`
+
+func (q *Queries) IsPostPruned(ctx context.Context, url string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isPostPruned, url)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const prunePostsForFeed = `-- name: PrunePostsForFeed :one
+WITH pruned AS (
+  DELETE FROM posts
+  WHERE posts.feed_id = $1
+    AND NOT EXISTS (
+      SELECT 1 FROM post_states
+      WHERE post_states.post_id = posts.id AND post_states.starred
+    )
+    AND (
+      COALESCE(posts.published_at, posts.created_at) < $2::timestamp
+      OR (
+        $3::integer IS NOT NULL
+        AND posts.id NOT IN (
+          SELECT newest.id FROM posts newest
+          WHERE newest.feed_id = $1
+          ORDER BY COALESCE(newest.published_at, newest.created_at) DESC
+          LIMIT $3
+        )
+      )
+    )
+  RETURNING posts.url, posts.feed_id
+), tombstones AS (
+  INSERT INTO pruned_posts (url, feed_id, pruned_at)
+  SELECT pruned.url, pruned.feed_id, $4 FROM pruned
+  ON CONFLICT (url) DO UPDATE SET pruned_at = EXCLUDED.pruned_at
+)
+SELECT count(*) FROM pruned
+`
+
+type PrunePostsForFeedParams struct {
+	FeedID          uuid.NullUUID
+	PublishedBefore sql.NullTime
+	KeepCount       sql.NullInt32
+	PrunedAt        time.Time
+}
+
+func (q *Queries) PrunePostsForFeed(ctx context.Context, arg PrunePostsForFeedParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, prunePostsForFeed,
+		arg.FeedID,
+		arg.PublishedBefore,
+		arg.KeepCount,
+		arg.PrunedAt,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
@@ -0,0 +1,77 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: prune_runs.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createPruneRun = `-- name: CreatePruneRun :one
+INSERT INTO prune_runs (id, created_at, removed_posts)
+VALUES ( $1, $2, $3 )
+RETURNING id, created_at, removed_posts
+`
+
+type CreatePruneRunParams struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	RemovedPosts int32
+}
+
+func (q *Queries) CreatePruneRun(ctx context.Context, arg CreatePruneRunParams) (PruneRun, error) {
+	row := q.db.QueryRowContext(ctx, createPruneRun, arg.ID, arg.CreatedAt, arg.RemovedPosts)
+	var i PruneRun
+	err := row.Scan(&i.ID, &i.CreatedAt, &i.RemovedPosts)
+	return i, err
+}
+
+const getRetentionDefaults = `-- name: GetRetentionDefaults :one
+SELECT id, max_age_hours, max_count, updated_at FROM retention_defaults
+`
+
+func (q *Queries) GetRetentionDefaults(ctx context.Context) (RetentionDefault, error) {
+	row := q.db.QueryRowContext(ctx, getRetentionDefaults)
+	var i RetentionDefault
+	err := row.Scan(
+		&i.ID,
+		&i.MaxAgeHours,
+		&i.MaxCount,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setRetentionDefaults = `-- name: SetRetentionDefaults :one
+INSERT INTO retention_defaults (id, max_age_hours, max_count, updated_at)
+VALUES ( TRUE, $1, $2, $3 )
+ON CONFLICT (id) DO UPDATE
+SET max_age_hours = EXCLUDED.max_age_hours,
+    max_count = EXCLUDED.max_count,
+    updated_at = EXCLUDED.updated_at
+RETURNING id, max_age_hours, max_count, updated_at
+`
+
+type SetRetentionDefaultsParams struct {
+	MaxAgeHours sql.NullInt32
+	MaxCount    sql.NullInt32
+	UpdatedAt   time.Time
+}
+
+func (q *Queries) SetRetentionDefaults(ctx context.Context, arg SetRetentionDefaultsParams) (RetentionDefault, error) {
+	row := q.db.QueryRowContext(ctx, setRetentionDefaults, arg.MaxAgeHours, arg.MaxCount, arg.UpdatedAt)
+	var i RetentionDefault
+	err := row.Scan(
+		&i.ID,
+		&i.MaxAgeHours,
+		&i.MaxCount,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,89 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: sessions.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createSession = `-- name: CreateSession :one
+INSERT INTO sessions (id, created_at, expires_at, user_id, token_hash)
+VALUES ( $1, $2, $3, $4, $5 )
+RETURNING id, created_at, expires_at, user_id, token_hash
+`
+
+type CreateSessionParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UserID    uuid.UUID
+	TokenHash string
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	row := q.db.QueryRowContext(ctx, createSession,
+		arg.ID,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+		arg.UserID,
+		arg.TokenHash,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.UserID,
+		&i.TokenHash,
+	)
+	return i, err
+}
+
+const deleteSessionByHash = `-- name: DeleteSessionByHash :exec
+DELETE FROM sessions
+WHERE token_hash = $1
+`
+
+func (q *Queries) DeleteSessionByHash(ctx context.Context, tokenHash string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionByHash, tokenHash)
+	return err
+}
+
+const deleteSessionsForUser = `-- name: DeleteSessionsForUser :exec
+DELETE FROM sessions
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteSessionsForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionsForUser, userID)
+	return err
+}
+
+const getSessionByHash = `-- name: GetSessionByHash :one
+SELECT id, created_at, expires_at, user_id, token_hash FROM sessions
+WHERE token_hash = $1 AND expires_at > $2
+`
+
+type GetSessionByHashParams struct {
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) GetSessionByHash(ctx context.Context, arg GetSessionByHashParams) (Session, error) {
+	row := q.db.QueryRowContext(ctx, getSessionByHash, arg.TokenHash, arg.ExpiresAt)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.UserID,
+		&i.TokenHash,
+	)
+	return i, err
+}
@@ -7,27 +7,67 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const countAdmins = `-- name: CountAdmins :one
+SELECT count(*) FROM users
+WHERE role = 'admin'
+`
+
+func (q *Queries) CountAdmins(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAdmins)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT count(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUsersWithPassword = `-- name: CountUsersWithPassword :one
+SELECT count(*) FROM users
+WHERE password_hash IS NOT NULL
+`
+
+func (q *Queries) CountUsersWithPassword(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsersWithPassword)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, password_hash, role)
 VALUES (
   $1,
   $2,
   $3,
-  $4
+  $4,
+  $5,
+  $6
 )
-RETURNING id, created_at, updated_at, name
+RETURNING id, created_at, updated_at, name, email, last_digest_at, fever_api_key, password_hash, role
 `
 
 type CreateUserParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string
+	PasswordHash sql.NullString
+	Role         string
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -36,6 +76,8 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.CreatedAt,
 		arg.UpdatedAt,
 		arg.Name,
+		arg.PasswordHash,
+		arg.Role,
 	)
 	var i User
 	err := row.Scan(
@@ -43,12 +85,27 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.FeverApiKey,
+		&i.PasswordHash,
+		&i.Role,
 	)
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
 const getAllUsers = `-- name: GetAllUsers :many
-SELECT id, created_at, updated_at, name FROM users
+SELECT id, created_at, updated_at, name, email, last_digest_at, fever_api_key, password_hash, role FROM users
 `
 
 func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
@@ -65,6 +122,11 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Name,
+			&i.Email,
+			&i.LastDigestAt,
+			&i.FeverApiKey,
+			&i.PasswordHash,
+			&i.Role,
 		); err != nil {
 			return nil, err
 		}
@@ -79,8 +141,35 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const getResetSummary = `-- name: GetResetSummary :one
+SELECT
+  (SELECT count(*) FROM users) AS user_count,
+  (SELECT count(*) FROM feeds) AS feed_count,
+  (SELECT count(*) FROM feed_follows) AS follow_count,
+  (SELECT count(*) FROM posts) AS post_count
+`
+
+type GetResetSummaryRow struct {
+	UserCount   int64
+	FeedCount   int64
+	FollowCount int64
+	PostCount   int64
+}
+
+func (q *Queries) GetResetSummary(ctx context.Context) (GetResetSummaryRow, error) {
+	row := q.db.QueryRowContext(ctx, getResetSummary)
+	var i GetResetSummaryRow
+	err := row.Scan(
+		&i.UserCount,
+		&i.FeedCount,
+		&i.FollowCount,
+		&i.PostCount,
+	)
+	return i, err
+}
+
 const getUser = `-- name: GetUser :one
-SELECT id, created_at, updated_at, name FROM users
+SELECT id, created_at, updated_at, name, email, last_digest_at, fever_api_key, password_hash, role FROM users
 WHERE name = $1
 `
 
@@ -92,12 +181,39 @@ func (q *Queries) GetUser(ctx context.Context, name string) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.FeverApiKey,
+		&i.PasswordHash,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getUserByFeverAPIKey = `-- name: GetUserByFeverAPIKey :one
+SELECT id, created_at, updated_at, name, email, last_digest_at, fever_api_key, password_hash, role FROM users
+WHERE fever_api_key = $1
+`
+
+func (q *Queries) GetUserByFeverAPIKey(ctx context.Context, feverApiKey sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByFeverAPIKey, feverApiKey)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.FeverApiKey,
+		&i.PasswordHash,
+		&i.Role,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, created_at, updated_at, name FROM users
+SELECT id, created_at, updated_at, name, email, last_digest_at, fever_api_key, password_hash, role FROM users
 WHERE id = $1
 `
 
@@ -109,6 +225,86 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.FeverApiKey,
+		&i.PasswordHash,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getUsersPage = `-- name: GetUsersPage :many
+SELECT id, created_at, updated_at, name, email, last_digest_at, fever_api_key, password_hash, role FROM users
+ORDER BY name
+LIMIT $1 OFFSET $2
+`
+
+type GetUsersPageParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) GetUsersPage(ctx context.Context, arg GetUsersPageParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersPage, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Email,
+			&i.LastDigestAt,
+			&i.FeverApiKey,
+			&i.PasswordHash,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const renameUser = `-- name: RenameUser :one
+UPDATE users
+SET name = $2, fever_api_key = NULL, updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, email, last_digest_at, fever_api_key, password_hash, role
+`
+
+type RenameUserParams struct {
+	ID        uuid.UUID
+	Name      string
+	UpdatedAt time.Time
+}
+
+// The Fever API key is derived from the username, so it stops working
+func (q *Queries) RenameUser(ctx context.Context, arg RenameUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, renameUser, arg.ID, arg.Name, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.FeverApiKey,
+		&i.PasswordHash,
+		&i.Role,
 	)
 	return i, err
 }
@@ -121,3 +317,100 @@ func (q *Queries) ResetUsers(ctx context.Context) error {
 	_, err := q.db.ExecContext(ctx, resetUsers)
 	return err
 }
+
+const setUserEmail = `-- name: SetUserEmail :one
+UPDATE users
+SET email = $2, updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, email, last_digest_at, fever_api_key, password_hash, role
+`
+
+type SetUserEmailParams struct {
+	ID        uuid.UUID
+	Email     sql.NullString
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetUserEmail(ctx context.Context, arg SetUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserEmail, arg.ID, arg.Email, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.FeverApiKey,
+		&i.PasswordHash,
+		&i.Role,
+	)
+	return i, err
+}
+
+const setUserFeverAPIKey = `-- name: SetUserFeverAPIKey :exec
+UPDATE users
+SET fever_api_key = $2, updated_at = $3
+WHERE id = $1
+`
+
+type SetUserFeverAPIKeyParams struct {
+	ID          uuid.UUID
+	FeverApiKey sql.NullString
+	UpdatedAt   time.Time
+}
+
+func (q *Queries) SetUserFeverAPIKey(ctx context.Context, arg SetUserFeverAPIKeyParams) error {
+	_, err := q.db.ExecContext(ctx, setUserFeverAPIKey, arg.ID, arg.FeverApiKey, arg.UpdatedAt)
+	return err
+}
+
+const setUserLastDigestAt = `-- name: SetUserLastDigestAt :exec
+UPDATE users
+SET last_digest_at = $2
+WHERE id = $1
+`
+
+type SetUserLastDigestAtParams struct {
+	ID           uuid.UUID
+	LastDigestAt sql.NullTime
+}
+
+func (q *Queries) SetUserLastDigestAt(ctx context.Context, arg SetUserLastDigestAtParams) error {
+	_, err := q.db.ExecContext(ctx, setUserLastDigestAt, arg.ID, arg.LastDigestAt)
+	return err
+}
+
+const setUserPassword = `-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $2, updated_at = $3
+WHERE id = $1
+`
+
+type SetUserPasswordParams struct {
+	ID           uuid.UUID
+	PasswordHash sql.NullString
+	UpdatedAt    time.Time
+}
+
+func (q *Queries) SetUserPassword(ctx context.Context, arg SetUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPassword, arg.ID, arg.PasswordHash, arg.UpdatedAt)
+	return err
+}
+
+const setUserRole = `-- name: SetUserRole :exec
+UPDATE users
+SET role = $2, updated_at = $3
+WHERE id = $1
+`
+
+type SetUserRoleParams struct {
+	ID        uuid.UUID
+	Role      string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) error {
+	_, err := q.db.ExecContext(ctx, setUserRole, arg.ID, arg.Role, arg.UpdatedAt)
+	return err
+}
@@ -0,0 +1,220 @@
+package digest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Digest is a set of posts grouped by the feed they came from. Truncated
+// is set when there were more posts than fit, which are left for the next
+// digest.
+type Digest struct {
+	Since     time.Time
+	Until     time.Time
+	Feeds     []Feed
+	Truncated bool
+}
+
+type Feed struct {
+	Name  string
+	Posts []Post
+}
+
+// Post is one entry in a digest. Summary is plain text and PublishedAt is
+// zero when the feed didn't give a date.
+type Post struct {
+	Title       string
+	URL         string
+	Summary     string
+	PublishedAt time.Time
+}
+
+func (d Digest) PostCount() int {
+	count := 0
+	for _, feed := range d.Feeds {
+		count += len(feed.Posts)
+	}
+	return count
+}
+
+// Subject returns the subject line for the digest email
+func (d Digest) Subject() string {
+	count := d.PostCount()
+	if count == 1 {
+		return "gator digest: 1 unread post"
+	}
+	return fmt.Sprintf("gator digest: %d unread posts", count)
+}
+
+// Text renders the plain-text body of the digest
+func (d Digest) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v since %v\n", d.Subject(), d.Since.Format(time.RFC1123))
+
+	for _, feed := range d.Feeds {
+		fmt.Fprintf(&b, "\n%v\n%v\n", feed.Name, strings.Repeat("=", len([]rune(feed.Name))))
+		for _, post := range feed.Posts {
+			fmt.Fprintf(&b, "\n* %v\n  %v\n", post.Title, post.URL)
+			if !post.PublishedAt.IsZero() {
+				fmt.Fprintf(&b, "  %v\n", post.PublishedAt.Format(time.RFC1123))
+			}
+			if post.Summary != "" {
+				fmt.Fprintf(&b, "\n  %v\n", strings.ReplaceAll(post.Summary, "\n", "\n  "))
+			}
+		}
+	}
+	if d.Truncated {
+		fmt.Fprintf(&b, "\n%v\n", truncatedNote)
+	}
+	return b.String()
+}
+
+var htmlTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format(time.RFC1123) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; max-width: 40em;">
+<h1>{{.Subject}}</h1>
+<p>Since {{date .Since}}</p>
+{{range .Feeds}}
+<h2>{{.Name}}</h2>
+{{range .Posts}}
+<div style="margin-bottom: 1.5em;">
+<a href="{{.URL}}"><strong>{{.Title}}</strong></a>
+{{if not .PublishedAt.IsZero}}<div style="color: #666; font-size: small;">{{date .PublishedAt}}</div>{{end}}
+{{if .Summary}}<p>{{.Summary}}</p>{{end}}
+</div>
+{{end}}
+{{end}}
+{{if .Truncated}}<p><em>{{.TruncatedNote}}</em></p>{{end}}
+</body>
+</html>
+`))
+
+const truncatedNote = "There were too many posts for one digest, the rest will be in the next one."
+
+func (d Digest) TruncatedNote() string {
+	return truncatedNote
+}
+
+// HTML renders the HTML body of the digest
+func (d Digest) HTML() (string, error) {
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, d); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Message builds a multipart/alternative email with the plain-text and
+// HTML versions of the digest
+func (d Digest) Message(from, to string) ([]byte, error) {
+	htmlBody, err := d.HTML()
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", d.Text()},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	headers := [][2]string{
+		{"From", from},
+		{"To", to},
+		{"Subject", mime.QEncoding.Encode("utf-8", d.Subject())},
+		{"Date", d.Until.Format(time.RFC1123Z)},
+		{"Message-ID", messageID(from)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + writer.Boundary()},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&msg, "%v: %v\r\n", header[0], header[1])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func messageID(from string) string {
+	b := make([]byte, 12)
+	rand.Read(b)
+
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = strings.Trim(from[at+1:], "> ")
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
+
+// Server is the SMTP server digests are sent through
+type Server struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// Send delivers msg to one recipient. Auth is only used when a username is
+// set, and net/smtp only allows it over TLS or to localhost.
+func Send(server Server, from, to string, msg []byte) error {
+	port := server.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(server.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if server.Username != "" {
+		auth = smtp.PlainAuth("", server.Username, server.Password, server.Host)
+	}
+	return smtp.SendMail(addr, auth, from, []string{to}, msg)
+}
+
+// Deliver sends the digest from one address to another and then calls
+// advance, which moves the start of the next digest. advance isn't called
+// when sending fails, so the same posts are sent next time.
+func Deliver(server Server, from *mail.Address, to string, d Digest, advance func() error) error {
+	msg, err := d.Message(from.String(), to)
+	if err != nil {
+		return err
+	}
+	if err := Send(server, from.Address, to, msg); err != nil {
+		return fmt.Errorf("could not send digest: %w", err)
+	}
+	return advance()
+}
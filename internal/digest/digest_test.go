@@ -0,0 +1,212 @@
+package digest
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpStandIn is a minimal SMTP server that accepts one message per
+// connection, or rejects the recipient when reject is set
+type smtpStandIn struct {
+	ln       net.Listener
+	reject   bool
+	messages chan string
+}
+
+func newSMTPStandIn(t *testing.T, reject bool) *smtpStandIn {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &smtpStandIn{ln: ln, reject: reject, messages: make(chan string, 1)}
+	t.Cleanup(func() { ln.Close() })
+	go srv.serve()
+	return srv
+}
+
+func (srv *smtpStandIn) server() Server {
+	addr := srv.ln.Addr().(*net.TCPAddr)
+	return Server{Host: "127.0.0.1", Port: addr.Port}
+}
+
+func (srv *smtpStandIn) serve() {
+	for {
+		conn, err := srv.ln.Accept()
+		if err != nil {
+			return
+		}
+		srv.handle(conn)
+	}
+}
+
+func (srv *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.Fields(line + " x")[0])
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL", "RSET", "NOOP":
+			reply("250 OK")
+		case "RCPT":
+			if srv.reject {
+				reply("550 no such mailbox")
+			} else {
+				reply("250 OK")
+			}
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			srv.messages <- data.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+var testDigest = Digest{
+	Since: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+	Until: time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC),
+	Feeds: []Feed{
+		{Name: "Alpha", Posts: []Post{
+			{Title: "Alpha one", URL: "https://alpha.example/1", Summary: "First & foremost"},
+			{Title: "Alpha two", URL: "https://alpha.example/2"},
+		}},
+		{Name: "Beta", Posts: []Post{
+			{Title: "Beta one", URL: "https://beta.example/1", PublishedAt: time.Date(2024, 7, 3, 12, 0, 0, 0, time.UTC)},
+		}},
+	},
+}
+
+var testFrom = &mail.Address{Name: "gator", Address: "gator@localhost"}
+
+func TestDeliver(t *testing.T) {
+	srv := newSMTPStandIn(t, false)
+
+	advanced := false
+	err := Deliver(srv.server(), testFrom, "reader@example.com", testDigest, func() error {
+		advanced = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !advanced {
+		t.Error("watermark was not advanced after a successful send")
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(<-srv.messages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Header.Get("Subject"); got != "gator digest: 3 unread posts" {
+		t.Errorf("Subject = %q", got)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", msg.Header.Get("Content-Type"))
+	}
+
+	parts := map[string]string{}
+	var order []string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[contentType] = strings.ReplaceAll(string(body), "\r\n", "\n")
+		order = append(order, contentType)
+	}
+	if strings.Join(order, ",") != "text/plain,text/html" {
+		t.Fatalf("parts = %v, want text/plain then text/html", order)
+	}
+
+	// Each feed's heading comes before its own posts and after the
+	// previous feed's
+	checkOrder(t, "text", parts["text/plain"], "Alpha\n=====", "Alpha one", "First & foremost", "Alpha two", "Beta\n====", "Beta one")
+	checkOrder(t, "html", parts["text/html"], "<h2>Alpha</h2>", `href="https://alpha.example/1"`, "First &amp; foremost", "Alpha two", "<h2>Beta</h2>", "Beta one")
+}
+
+func TestDeliverFailureKeepsWatermark(t *testing.T) {
+	srv := newSMTPStandIn(t, true)
+
+	advanced := false
+	err := Deliver(srv.server(), testFrom, "nobody@example.com", testDigest, func() error {
+		advanced = true
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Deliver succeeded although the recipient was rejected")
+	}
+	if advanced {
+		t.Error("watermark was advanced although sending failed")
+	}
+}
+
+func TestTruncatedNote(t *testing.T) {
+	d := testDigest
+	d.Truncated = true
+
+	if !strings.Contains(d.Text(), truncatedNote) {
+		t.Error("plain-text digest is missing the truncation note")
+	}
+	html, err := d.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, truncatedNote) {
+		t.Error("HTML digest is missing the truncation note")
+	}
+}
+
+func checkOrder(t *testing.T, name, body string, want ...string) {
+	t.Helper()
+	rest := body
+	for _, s := range want {
+		i := strings.Index(rest, s)
+		if i < 0 {
+			t.Errorf("%v part: %q missing or out of order in:\n%v", name, s, body)
+			return
+		}
+		rest = rest[i+len(s):]
+	}
+}
@@ -0,0 +1,408 @@
+package fever
+
+import (
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	apiVersion = 3
+	// itemsPerRequest is the page size the Fever API specifies
+	itemsPerRequest = 50
+)
+
+// APIKey returns the key Fever clients send for a username and password:
+// the hex encoded MD5 of "username:password"
+func APIKey(username, password string) string {
+	sum := md5.Sum([]byte(username + ":" + password))
+	return hex.EncodeToString(sum[:])
+}
+
+// Server implements the Fever API over gator's feeds and posts. Folders
+// are reported as groups and posts as items.
+type Server struct {
+	db *database.Queries
+}
+
+func New(db *database.Queries) *Server {
+	return &Server{db: db}
+}
+
+type response map[string]any
+
+type group struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+type feedGroup struct {
+	GroupID int    `json:"group_id"`
+	FeedIDs string `json:"feed_ids"`
+}
+
+type feed struct {
+	ID                int64  `json:"id"`
+	FaviconID         int64  `json:"favicon_id"`
+	Title             string `json:"title"`
+	URL               string `json:"url"`
+	SiteURL           string `json:"site_url"`
+	IsSpark           int    `json:"is_spark"`
+	LastUpdatedOnTime int64  `json:"last_updated_on_time"`
+}
+
+type item struct {
+	ID            int64  `json:"id"`
+	FeedID        int64  `json:"feed_id"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	HTML          string `json:"html"`
+	URL           string `json:"url"`
+	IsSaved       int    `json:"is_saved"`
+	IsRead        int    `json:"is_read"`
+	CreatedOnTime int64  `json:"created_on_time"`
+}
+
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if !query.Has("api") {
+		http.NotFound(w, r)
+		return
+	}
+
+	resp := response{"api_version": apiVersion, "auth": 0}
+
+	user, err := srv.db.GetUserByFeverAPIKey(r.Context(), sql.NullString{String: strings.ToLower(r.FormValue("api_key")), Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSON(w, resp)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp["auth"] = 1
+	resp["last_refreshed_on_time"] = time.Now().Unix()
+
+	if err := srv.mark(r, user, resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if query.Has("groups") || query.Has("feeds") {
+		feeds, groups, feedsGroups, err := srv.feeds(r, user)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if query.Has("groups") {
+			resp["groups"] = groups
+		}
+		if query.Has("feeds") {
+			resp["feeds"] = feeds
+		}
+		resp["feeds_groups"] = feedsGroups
+	}
+
+	// gator doesn't store favicons and has no hot links
+	if query.Has("favicons") {
+		resp["favicons"] = []any{}
+	}
+	if query.Has("links") {
+		resp["links"] = []any{}
+	}
+
+	if query.Has("items") {
+		if err := srv.items(r, user, resp); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if query.Has("unread_item_ids") {
+		if err := srv.unreadItemIDs(r, user, resp); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if query.Has("saved_item_ids") {
+		if err := srv.savedItemIDs(r, user, resp); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeJSON(w, resp)
+}
+
+// feeds returns the user's followed feeds and their folders as groups.
+// Group ids are the position of the folder in name order, starting at 1.
+func (srv *Server) feeds(r *http.Request, user database.User) ([]feed, []group, []feedGroup, error) {
+	rows, err := srv.db.GetFeverFeedsForUser(r.Context(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	feeds := make([]feed, 0, len(rows))
+	feedIDs := map[string][]string{}
+	for _, row := range rows {
+		f := feed{
+			ID:      row.FeverID,
+			Title:   row.Name,
+			URL:     row.Url,
+			SiteURL: row.SiteUrl.String,
+		}
+		if row.LastFetchedAt.Valid {
+			f.LastUpdatedOnTime = row.LastFetchedAt.Time.Unix()
+		}
+		feeds = append(feeds, f)
+
+		if row.Folder.Valid {
+			feedIDs[row.Folder.String] = append(feedIDs[row.Folder.String], strconv.FormatInt(row.FeverID, 10))
+		}
+	}
+
+	names := folderNames(rows)
+	groups := make([]group, 0, len(names))
+	feedsGroups := make([]feedGroup, 0, len(names))
+	for i, name := range names {
+		groups = append(groups, group{ID: i + 1, Title: name})
+		feedsGroups = append(feedsGroups, feedGroup{GroupID: i + 1, FeedIDs: strings.Join(feedIDs[name], ",")})
+	}
+	return feeds, groups, feedsGroups, nil
+}
+
+func folderNames(rows []database.GetFeverFeedsForUserRow) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, row := range rows {
+		if row.Folder.Valid && !seen[row.Folder.String] {
+			seen[row.Folder.String] = true
+			names = append(names, row.Folder.String)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// items returns up to 50 items, either those listed in with_ids, those
+// older than max_id, or those newer than since_id
+func (srv *Server) items(r *http.Request, user database.User, resp response) error {
+	userID := uuid.NullUUID{UUID: user.ID, Valid: true}
+	query := r.URL.Query()
+
+	var rows []database.GetFeverItemsSinceRow
+	switch {
+	case query.Get("with_ids") != "":
+		var ids []int64
+		for _, value := range strings.Split(query.Get("with_ids"), ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err == nil {
+				ids = append(ids, id)
+			}
+			if len(ids) == itemsPerRequest {
+				break
+			}
+		}
+		found, err := srv.db.GetFeverItemsByIDs(r.Context(), database.GetFeverItemsByIDsParams{UserID: userID, Ids: ids})
+		if err != nil {
+			return err
+		}
+		for _, row := range found {
+			rows = append(rows, database.GetFeverItemsSinceRow(row))
+		}
+	case query.Get("max_id") != "":
+		maxID, _ := strconv.ParseInt(query.Get("max_id"), 10, 64)
+		found, err := srv.db.GetFeverItemsBefore(r.Context(), database.GetFeverItemsBeforeParams{UserID: userID, MaxID: maxID, RowLimit: itemsPerRequest})
+		if err != nil {
+			return err
+		}
+		for _, row := range found {
+			rows = append(rows, database.GetFeverItemsSinceRow(row))
+		}
+	default:
+		sinceID, _ := strconv.ParseInt(query.Get("since_id"), 10, 64)
+		found, err := srv.db.GetFeverItemsSince(r.Context(), database.GetFeverItemsSinceParams{UserID: userID, SinceID: sinceID, RowLimit: itemsPerRequest})
+		if err != nil {
+			return err
+		}
+		rows = found
+	}
+
+	items := make([]item, 0, len(rows))
+	for _, row := range rows {
+		html := row.Description.String
+		if row.Content.Valid {
+			html = row.Content.String
+		}
+		createdOn := row.CreatedAt
+		if row.PublishedAt.Valid {
+			createdOn = row.PublishedAt.Time
+		}
+
+		items = append(items, item{
+			ID:            row.FeverID,
+			FeedID:        row.FeedFeverID,
+			Title:         row.Title.String,
+			Author:        row.Author.String,
+			HTML:          html,
+			URL:           row.Url,
+			IsSaved:       boolInt(row.Starred),
+			IsRead:        boolInt(row.Read),
+			CreatedOnTime: createdOn.Unix(),
+		})
+	}
+
+	total, err := srv.db.CountPostsForUser(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp["items"] = items
+	resp["total_items"] = total
+	return nil
+}
+
+func (srv *Server) unreadItemIDs(r *http.Request, user database.User, resp response) error {
+	ids, err := srv.db.GetUnreadFeverIDsForUser(r.Context(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		return err
+	}
+	resp["unread_item_ids"] = joinIDs(ids)
+	return nil
+}
+
+func (srv *Server) savedItemIDs(r *http.Request, user database.User, resp response) error {
+	ids, err := srv.db.GetStarredFeverIDsForUser(r.Context(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		return err
+	}
+	resp["saved_item_ids"] = joinIDs(ids)
+	return nil
+}
+
+// mark applies a mark=item|feed|group write. Unknown targets and ids are
+// ignored, as Fever does.
+func (srv *Server) mark(r *http.Request, user database.User, resp response) error {
+	target := r.FormValue("mark")
+	if target == "" {
+		return nil
+	}
+	as := r.FormValue("as")
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	switch target {
+	case "item":
+		return srv.markItem(r, user, id, as, resp)
+	case "feed", "group":
+		if as != "read" {
+			return nil
+		}
+		return srv.markRead(r, user, target, id)
+	}
+	return nil
+}
+
+func (srv *Server) markItem(r *http.Request, user database.User, id int64, as string, resp response) error {
+	postID, err := srv.db.GetPostIDByFeverIDForUser(r.Context(), database.GetPostIDByFeverIDForUserParams{
+		UserID:  uuid.NullUUID{UUID: user.ID, Valid: true},
+		FeverID: id,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch as {
+	case "read", "unread":
+		err := srv.db.SetPostRead(r.Context(), database.SetPostReadParams{
+			UserID:    user.ID,
+			PostID:    postID,
+			CreatedAt: time.Now(),
+			Read:      as == "read",
+		})
+		if err != nil {
+			return err
+		}
+		return srv.unreadItemIDs(r, user, resp)
+	case "saved", "unsaved":
+		err := srv.db.SetPostStarred(r.Context(), database.SetPostStarredParams{
+			UserID:    user.ID,
+			PostID:    postID,
+			CreatedAt: time.Now(),
+			Starred:   as == "saved",
+		})
+		if err != nil {
+			return err
+		}
+		return srv.savedItemIDs(r, user, resp)
+	}
+	return nil
+}
+
+// markRead marks a feed or a group read up to the "before" timestamp.
+// Group 0 is Fever's group of all feeds.
+func (srv *Server) markRead(r *http.Request, user database.User, target string, id int64) error {
+	before := time.Now()
+	if value, err := strconv.ParseInt(r.FormValue("before"), 10, 64); err == nil && value > 0 {
+		before = time.Unix(value, 0)
+	}
+
+	params := database.MarkPostsReadForUserParams{
+		Now:    time.Now(),
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Before: before,
+	}
+
+	if target == "feed" {
+		params.FeedFeverID = sql.NullInt64{Int64: id, Valid: true}
+	} else if id != 0 {
+		rows, err := srv.db.GetFeverFeedsForUser(r.Context(), uuid.NullUUID{UUID: user.ID, Valid: true})
+		if err != nil {
+			return err
+		}
+		names := folderNames(rows)
+		if id < 1 || int(id) > len(names) {
+			return nil
+		}
+		params.Folder = sql.NullString{String: names[id-1], Valid: true}
+	}
+
+	_, err := srv.db.MarkPostsReadForUser(r.Context(), params)
+	return err
+}
+
+func joinIDs(ids []int64) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func writeJSON(w http.ResponseWriter, resp response) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,159 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ActionInclude = "include"
+	ActionExclude = "exclude"
+)
+
+const (
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldAuthor      = "author"
+	FieldCategory    = "category"
+	FieldAny         = "any"
+)
+
+const (
+	MatchKeyword = "keyword"
+	MatchRegex   = "regex"
+)
+
+// Post holds the parts of a post that rules can match on. Description
+// should already be converted from HTML to text.
+type Post struct {
+	FeedID      uuid.UUID
+	Title       string
+	Description string
+	Author      string
+	Categories  []string
+}
+
+// Matcher tests one field of a post against a case-insensitive keyword or
+// a regular expression
+type Matcher struct {
+	Field     string
+	MatchType string
+	Pattern   string
+
+	re *regexp.Regexp
+}
+
+func NewMatcher(field, matchType, pattern string) (Matcher, error) {
+	switch field {
+	case FieldTitle, FieldDescription, FieldAuthor, FieldCategory, FieldAny:
+	default:
+		return Matcher{}, fmt.Errorf("unknown field %q, expected title, description, author, category or any", field)
+	}
+
+	m := Matcher{Field: field, MatchType: matchType, Pattern: pattern}
+	switch matchType {
+	case MatchKeyword:
+		if strings.TrimSpace(pattern) == "" {
+			return Matcher{}, fmt.Errorf("keyword can't be empty")
+		}
+	case MatchRegex:
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return Matcher{}, err
+		}
+		m.re = re
+	default:
+		return Matcher{}, fmt.Errorf("unknown match type %q, expected keyword or regex", matchType)
+	}
+	return m, nil
+}
+
+func (m Matcher) Match(p Post) bool {
+	var values []string
+	switch m.Field {
+	case FieldTitle:
+		values = []string{p.Title}
+	case FieldDescription:
+		values = []string{p.Description}
+	case FieldAuthor:
+		values = []string{p.Author}
+	case FieldCategory:
+		values = p.Categories
+	case FieldAny:
+		values = append([]string{p.Title, p.Description, p.Author}, p.Categories...)
+	}
+
+	for _, value := range values {
+		if m.matchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Matcher) matchString(s string) bool {
+	if m.re != nil {
+		return m.re.MatchString(s)
+	}
+	return strings.Contains(strings.ToLower(s), strings.ToLower(m.Pattern))
+}
+
+// Rule includes or excludes the posts its matcher accepts, either for one
+// feed or, when FeedID is not set, for every feed
+type Rule struct {
+	ID     uuid.UUID
+	FeedID uuid.NullUUID
+	Action string
+	Matcher
+}
+
+func NewRule(id uuid.UUID, feedID uuid.NullUUID, action, field, matchType, pattern string) (Rule, error) {
+	if action != ActionInclude && action != ActionExclude {
+		return Rule{}, fmt.Errorf("unknown action %q, expected include or exclude", action)
+	}
+	m, err := NewMatcher(field, matchType, pattern)
+	if err != nil {
+		return Rule{}, err
+	}
+	return Rule{ID: id, FeedID: feedID, Action: action, Matcher: m}, nil
+}
+
+func (r Rule) AppliesTo(p Post) bool {
+	return !r.FeedID.Valid || r.FeedID.UUID == p.FeedID
+}
+
+// Evaluate decides whether p should be shown. Any matching exclude rule
+// hides the post. If include rules apply to the post's feed, at least one
+// of them must match. The deciding rule is returned when there is one; a
+// hidden post with no rule means no include rule matched.
+func Evaluate(rules []Rule, p Post) (bool, *Rule) {
+	hasInclude := false
+	var included *Rule
+
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.AppliesTo(p) {
+			continue
+		}
+
+		switch rule.Action {
+		case ActionExclude:
+			if rule.Match(p) {
+				return false, rule
+			}
+		case ActionInclude:
+			hasInclude = true
+			if included == nil && rule.Match(p) {
+				included = rule
+			}
+		}
+	}
+
+	if hasInclude && included == nil {
+		return false, nil
+	}
+	return true, included
+}
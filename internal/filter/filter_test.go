@@ -0,0 +1,71 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var testPost = Post{
+	FeedID:      uuid.New(),
+	Title:       "Gator 1.0 released",
+	Description: "The terminal feed reader now supports alerts.",
+	Author:      "Ann Example",
+	Categories:  []string{"Releases", "Go"},
+}
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		name      string
+		field     string
+		matchType string
+		pattern   string
+		want      bool
+	}{
+		{"keyword in title", FieldTitle, MatchKeyword, "gator", true},
+		{"keyword ignores case", FieldTitle, MatchKeyword, "RELEASED", true},
+		{"keyword in other field", FieldTitle, MatchKeyword, "terminal", false},
+		{"keyword in description", FieldDescription, MatchKeyword, "feed reader", true},
+		{"keyword in author", FieldAuthor, MatchKeyword, "ann", true},
+		{"keyword in category", FieldCategory, MatchKeyword, "go", true},
+		{"keyword in any field", FieldAny, MatchKeyword, "alerts", true},
+		{"keyword nowhere", FieldAny, MatchKeyword, "rust", false},
+		{"regex", FieldTitle, MatchRegex, `^gator \d+\.\d+`, true},
+		{"regex ignores case", FieldCategory, MatchRegex, `^releases$`, true},
+		{"regex no match", FieldTitle, MatchRegex, `^released`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMatcher(tt.field, tt.matchType, tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.Match(testPost); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMatcherInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		field     string
+		matchType string
+		pattern   string
+	}{
+		{"unknown field", "body", MatchKeyword, "gator"},
+		{"unknown match type", FieldTitle, "glob", "gator*"},
+		{"empty keyword", FieldTitle, MatchKeyword, "  "},
+		{"bad regex", FieldTitle, MatchRegex, "(unclosed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMatcher(tt.field, tt.matchType, tt.pattern); err == nil {
+				t.Error("NewMatcher() succeeded, want an error")
+			}
+		})
+	}
+}
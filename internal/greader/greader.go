@@ -0,0 +1,578 @@
+package greader
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/api"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	streamReadingList = "user/-/state/com.google/reading-list"
+	streamRead        = "user/-/state/com.google/read"
+	streamStarred     = "user/-/state/com.google/starred"
+	labelPrefix       = "user/-/label/"
+	feedPrefix        = "feed/"
+	itemIDPrefix      = "tag:google.com,2005:reader/item/"
+
+	defaultItemCount = 20
+	maxItemCount     = 1000
+)
+
+// Server implements the part of the Google Reader API that sync clients
+// use: ClientLogin, the subscription and tag lists, stream contents and
+// item ids, and edit-tag for read and starred state. Clients log in with
+// their gator username and an API key as the password.
+type Server struct {
+	db  *database.Queries
+	mux *http.ServeMux
+}
+
+func New(db *database.Queries) *Server {
+	srv := &Server{db: db, mux: http.NewServeMux()}
+
+	srv.mux.HandleFunc("POST /accounts/ClientLogin", srv.handleClientLogin)
+
+	srv.handle("GET /reader/api/0/token", srv.handleToken)
+	srv.handle("GET /reader/api/0/user-info", srv.handleUserInfo)
+	srv.handle("GET /reader/api/0/subscription/list", srv.handleSubscriptionList)
+	srv.handle("GET /reader/api/0/tag/list", srv.handleTagList)
+	srv.handle("GET /reader/api/0/unread-count", srv.handleUnreadCount)
+	srv.handle("GET /reader/api/0/stream/contents/{stream...}", srv.handleStreamContents)
+	srv.handle("GET /reader/api/0/stream/items/ids", srv.handleItemIDs)
+	srv.handle("POST /reader/api/0/stream/items/contents", srv.handleItemContents)
+	srv.handle("POST /reader/api/0/edit-tag", srv.handleEditTag)
+	srv.handle("POST /reader/api/0/mark-all-as-read", srv.handleMarkAllAsRead)
+
+	return srv
+}
+
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv.mux.ServeHTTP(w, r)
+}
+
+func (srv *Server) handle(pattern string, h func(w http.ResponseWriter, r *http.Request, user database.User)) {
+	srv.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "GoogleLogin auth=")
+		if !ok || token == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		user, err := srv.userForKey(r, token)
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h(w, r, user)
+	})
+}
+
+// userForKey looks up the user an API key belongs to
+func (srv *Server) userForKey(r *http.Request, key string) (database.User, error) {
+	apiKey, err := srv.db.GetAPIKeyByHash(r.Context(), api.HashKey(key))
+	if err != nil {
+		return database.User{}, err
+	}
+	return srv.db.GetUserByID(r.Context(), apiKey.UserID)
+}
+
+// handleClientLogin checks the username and API key and hands the key
+// back as the auth token for later requests
+func (srv *Server) handleClientLogin(w http.ResponseWriter, r *http.Request) {
+	user, err := srv.userForKey(r, r.FormValue("Passwd"))
+	if err != nil || user.Name != r.FormValue("Email") {
+		http.Error(w, "Error=BadAuthentication", http.StatusUnauthorized)
+		return
+	}
+
+	token := r.FormValue("Passwd")
+	if r.FormValue("output") == "json" {
+		writeJSON(w, map[string]string{"SID": token, "LSID": token, "Auth": token})
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "SID=%v\nLSID=%v\nAuth=%v\n", token, token, token)
+}
+
+// handleToken returns the token clients send with edits. Requests are
+// already authenticated by header, so it is not checked.
+func (srv *Server) handleToken(w http.ResponseWriter, r *http.Request, user database.User) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, strings.ReplaceAll(user.ID.String(), "-", ""))
+}
+
+func (srv *Server) handleUserInfo(w http.ResponseWriter, r *http.Request, user database.User) {
+	writeJSON(w, map[string]string{
+		"userId":        user.ID.String(),
+		"userName":      user.Name,
+		"userProfileId": user.ID.String(),
+		"userEmail":     user.Email.String,
+	})
+}
+
+type category struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type subscription struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Categories []category `json:"categories"`
+	URL        string     `json:"url"`
+	HTMLURL    string     `json:"htmlUrl"`
+	IconURL    string     `json:"iconUrl"`
+}
+
+func (srv *Server) handleSubscriptionList(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := srv.db.GetFeverFeedsForUser(r.Context(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	subscriptions := make([]subscription, 0, len(feeds))
+	for _, feed := range feeds {
+		sub := subscription{
+			ID:         feedPrefix + strconv.FormatInt(feed.FeverID, 10),
+			Title:      feed.Name,
+			Categories: []category{},
+			URL:        feed.Url,
+			HTMLURL:    feed.SiteUrl.String,
+		}
+		if feed.Folder.Valid {
+			sub.Categories = append(sub.Categories, category{ID: labelPrefix + feed.Folder.String, Label: feed.Folder.String})
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	writeJSON(w, map[string]any{"subscriptions": subscriptions})
+}
+
+func (srv *Server) handleTagList(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := srv.db.GetFeverFeedsForUser(r.Context(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	type tag struct {
+		ID   string `json:"id"`
+		Type string `json:"type,omitempty"`
+	}
+	tags := []tag{{ID: streamStarred}}
+	seen := map[string]bool{}
+	for _, feed := range feeds {
+		if feed.Folder.Valid && !seen[feed.Folder.String] {
+			seen[feed.Folder.String] = true
+			tags = append(tags, tag{ID: labelPrefix + feed.Folder.String, Type: "folder"})
+		}
+	}
+	writeJSON(w, map[string]any{"tags": tags})
+}
+
+func (srv *Server) handleUnreadCount(w http.ResponseWriter, r *http.Request, user database.User) {
+	rows, err := srv.db.GetReaderItems(r.Context(), database.GetReaderItemsParams{
+		UserID:     uuid.NullUUID{UUID: user.ID, Valid: true},
+		UnreadOnly: true,
+		RowLimit:   maxItemCount,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	type count struct {
+		ID                      string `json:"id"`
+		Count                   int    `json:"count"`
+		NewestItemTimestampUsec string `json:"newestItemTimestampUsec"`
+	}
+	counts := map[string]*count{}
+	var order []string
+	add := func(id string, row database.GetReaderItemsRow) {
+		c, ok := counts[id]
+		if !ok {
+			c = &count{ID: id}
+			counts[id] = c
+			order = append(order, id)
+		}
+		c.Count++
+		if usec := strconv.FormatInt(row.CreatedAt.UnixMicro(), 10); c.NewestItemTimestampUsec == "" {
+			c.NewestItemTimestampUsec = usec
+		}
+	}
+	for _, row := range rows {
+		add(streamReadingList, row)
+		add(feedPrefix+strconv.FormatInt(row.FeedFeverID, 10), row)
+		if row.Folder.Valid {
+			add(labelPrefix+row.Folder.String, row)
+		}
+	}
+
+	unreadcounts := make([]count, 0, len(order))
+	for _, id := range order {
+		unreadcounts = append(unreadcounts, *counts[id])
+	}
+	writeJSON(w, map[string]any{"max": maxItemCount, "unreadcounts": unreadcounts})
+}
+
+// streamParams turns a stream id and the usual query parameters into a
+// query. It reports false for stream ids it doesn't know.
+func streamParams(r *http.Request, user database.User, stream string) (database.GetReaderItemsParams, bool) {
+	params := database.GetReaderItemsParams{
+		UserID:   uuid.NullUUID{UUID: user.ID, Valid: true},
+		RowLimit: defaultItemCount,
+	}
+
+	switch stream = normalizeStream(stream); {
+	case stream == "" || stream == streamReadingList:
+	case stream == streamStarred:
+		params.StarredOnly = true
+	case stream == streamRead:
+		params.ReadOnly = true
+	case strings.HasPrefix(stream, labelPrefix):
+		params.Folder = sql.NullString{String: strings.TrimPrefix(stream, labelPrefix), Valid: true}
+	case strings.HasPrefix(stream, feedPrefix):
+		id, err := strconv.ParseInt(strings.TrimPrefix(stream, feedPrefix), 10, 64)
+		if err != nil {
+			return params, false
+		}
+		params.FeedFeverID = sql.NullInt64{Int64: id, Valid: true}
+	default:
+		return params, false
+	}
+
+	query := r.URL.Query()
+	if n, err := strconv.Atoi(query.Get("n")); err == nil && n > 0 {
+		params.RowLimit = int32(min(n, maxItemCount))
+	}
+	if offset, err := strconv.Atoi(query.Get("c")); err == nil && offset > 0 {
+		params.RowOffset = int32(offset)
+	}
+	if ot, err := strconv.ParseInt(query.Get("ot"), 10, 64); err == nil && ot > 0 {
+		params.NewerThan = sql.NullTime{Time: time.Unix(ot, 0), Valid: true}
+	}
+	params.OldestFirst = query.Get("r") == "o"
+	for _, exclude := range query["xt"] {
+		if normalizeStream(exclude) == streamRead {
+			params.UnreadOnly = true
+		}
+	}
+	for _, include := range query["it"] {
+		switch normalizeStream(include) {
+		case streamStarred:
+			params.StarredOnly = true
+		case streamRead:
+			params.ReadOnly = true
+		}
+	}
+	return params, true
+}
+
+// normalizeStream rewrites "user/<id>/..." stream ids to "user/-/..."
+func normalizeStream(stream string) string {
+	if rest, ok := strings.CutPrefix(stream, "user/"); ok {
+		if _, after, ok := strings.Cut(rest, "/"); ok {
+			return "user/-/" + after
+		}
+	}
+	return stream
+}
+
+type itemLink struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type itemOrigin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+type itemContent struct {
+	Direction string `json:"direction"`
+	Content   string `json:"content"`
+}
+
+type item struct {
+	ID            string      `json:"id"`
+	CrawlTimeMsec string      `json:"crawlTimeMsec"`
+	TimestampUsec string      `json:"timestampUsec"`
+	Published     int64       `json:"published"`
+	Updated       int64       `json:"updated"`
+	Title         string      `json:"title"`
+	Author        string      `json:"author,omitempty"`
+	Canonical     []itemLink  `json:"canonical"`
+	Alternate     []itemLink  `json:"alternate"`
+	Categories    []string    `json:"categories"`
+	Origin        itemOrigin  `json:"origin"`
+	Summary       itemContent `json:"summary"`
+}
+
+func newItem(row database.GetReaderItemsRow) item {
+	published := row.CreatedAt
+	if row.PublishedAt.Valid {
+		published = row.PublishedAt.Time
+	}
+	content := row.Description.String
+	if row.Content.Valid {
+		content = row.Content.String
+	}
+
+	categories := []string{streamReadingList}
+	if row.Folder.Valid {
+		categories = append(categories, labelPrefix+row.Folder.String)
+	}
+	if row.Read {
+		categories = append(categories, streamRead)
+	}
+	if row.Starred {
+		categories = append(categories, streamStarred)
+	}
+
+	return item{
+		ID:            longItemID(row.FeverID),
+		CrawlTimeMsec: strconv.FormatInt(row.CreatedAt.UnixMilli(), 10),
+		TimestampUsec: strconv.FormatInt(published.UnixMicro(), 10),
+		Published:     published.Unix(),
+		Updated:       published.Unix(),
+		Title:         row.Title.String,
+		Author:        row.Author.String,
+		Canonical:     []itemLink{{Href: row.Url}},
+		Alternate:     []itemLink{{Href: row.Url, Type: "text/html"}},
+		Categories:    categories,
+		Origin: itemOrigin{
+			StreamID: feedPrefix + strconv.FormatInt(row.FeedFeverID, 10),
+			Title:    row.FeedName,
+			HTMLURL:  row.FeedSiteUrl.String,
+		},
+		Summary: itemContent{Direction: "ltr", Content: content},
+	}
+}
+
+func writeItems(w http.ResponseWriter, stream string, params database.GetReaderItemsParams, rows []database.GetReaderItemsRow) {
+	items := make([]item, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, newItem(row))
+	}
+
+	resp := map[string]any{
+		"id":      stream,
+		"updated": time.Now().Unix(),
+		"items":   items,
+	}
+	if len(rows) == int(params.RowLimit) {
+		resp["continuation"] = strconv.Itoa(int(params.RowOffset + params.RowLimit))
+	}
+	writeJSON(w, resp)
+}
+
+func (srv *Server) handleStreamContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	stream := r.PathValue("stream")
+	params, ok := streamParams(r, user, stream)
+	if !ok {
+		http.Error(w, "unknown stream", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := srv.db.GetReaderItems(r.Context(), params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeItems(w, stream, params, rows)
+}
+
+func (srv *Server) handleItemIDs(w http.ResponseWriter, r *http.Request, user database.User) {
+	stream := r.URL.Query().Get("s")
+	params, ok := streamParams(r, user, stream)
+	if !ok {
+		http.Error(w, "unknown stream", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := srv.db.GetReaderItems(r.Context(), params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	type itemRef struct {
+		ID string `json:"id"`
+	}
+	refs := make([]itemRef, 0, len(rows))
+	for _, row := range rows {
+		refs = append(refs, itemRef{ID: strconv.FormatInt(row.FeverID, 10)})
+	}
+
+	resp := map[string]any{"itemRefs": refs}
+	if len(rows) == int(params.RowLimit) {
+		resp["continuation"] = strconv.Itoa(int(params.RowOffset + params.RowLimit))
+	}
+	writeJSON(w, resp)
+}
+
+func (srv *Server) handleItemContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	ids, ok := parseItemIDs(w, r)
+	if !ok {
+		return
+	}
+
+	params := database.GetReaderItemsParams{
+		UserID:   uuid.NullUUID{UUID: user.ID, Valid: true},
+		Ids:      ids,
+		RowLimit: maxItemCount,
+	}
+	rows, err := srv.db.GetReaderItems(r.Context(), params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeItems(w, streamReadingList, params, rows)
+}
+
+// handleEditTag adds or removes the read and starred states of items.
+// Other tags are accepted and ignored.
+func (srv *Server) handleEditTag(w http.ResponseWriter, r *http.Request, user database.User) {
+	ids, ok := parseItemIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var read, starred *bool
+	set := func(tags []string, value bool) {
+		for _, tag := range tags {
+			switch normalizeStream(tag) {
+			case streamRead:
+				read = &value
+			case streamStarred:
+				starred = &value
+			}
+		}
+	}
+	set(r.Form["a"], true)
+	set(r.Form["r"], false)
+
+	rows, err := srv.db.GetReaderItems(r.Context(), database.GetReaderItemsParams{
+		UserID:   uuid.NullUUID{UUID: user.ID, Valid: true},
+		Ids:      ids,
+		RowLimit: maxItemCount,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, row := range rows {
+		if read != nil {
+			err = srv.db.SetPostRead(r.Context(), database.SetPostReadParams{
+				UserID:    user.ID,
+				PostID:    row.ID,
+				CreatedAt: time.Now(),
+				Read:      *read,
+			})
+		}
+		if err == nil && starred != nil {
+			err = srv.db.SetPostStarred(r.Context(), database.SetPostStarredParams{
+				UserID:    user.ID,
+				PostID:    row.ID,
+				CreatedAt: time.Now(),
+				Starred:   *starred,
+			})
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	writeOK(w)
+}
+
+func (srv *Server) handleMarkAllAsRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	params := database.MarkPostsReadForUserParams{
+		Now:    time.Now(),
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Before: time.Now(),
+	}
+	// ts is in microseconds
+	if ts, err := strconv.ParseInt(r.FormValue("ts"), 10, 64); err == nil && ts > 0 {
+		params.Before = time.UnixMicro(ts)
+	}
+
+	switch stream := normalizeStream(r.FormValue("s")); {
+	case stream == "" || stream == streamReadingList:
+	case strings.HasPrefix(stream, labelPrefix):
+		params.Folder = sql.NullString{String: strings.TrimPrefix(stream, labelPrefix), Valid: true}
+	case strings.HasPrefix(stream, feedPrefix):
+		id, err := strconv.ParseInt(strings.TrimPrefix(stream, feedPrefix), 10, 64)
+		if err != nil {
+			http.Error(w, "unknown stream", http.StatusBadRequest)
+			return
+		}
+		params.FeedFeverID = sql.NullInt64{Int64: id, Valid: true}
+	default:
+		http.Error(w, "unknown stream", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := srv.db.MarkPostsReadForUser(r.Context(), params); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeOK(w)
+}
+
+// parseItemIDs reads the "i" form values, which may be long
+// "tag:google.com,2005:reader/item/<hex>" ids or short decimal ones
+func parseItemIDs(w http.ResponseWriter, r *http.Request) ([]int64, bool) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	ids := []int64{}
+	for _, value := range r.Form["i"] {
+		id, err := parseItemID(value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+func parseItemID(value string) (int64, error) {
+	if hex, ok := strings.CutPrefix(value, itemIDPrefix); ok {
+		id, err := strconv.ParseUint(hex, 16, 64)
+		return int64(id), err
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid item id " + value)
+	}
+	return id, nil
+}
+
+func longItemID(id int64) string {
+	return fmt.Sprintf("%v%016x", itemIDPrefix, id)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeOK(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "OK")
+}
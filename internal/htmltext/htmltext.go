@@ -0,0 +1,406 @@
+package htmltext
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type Options struct {
+	// Width is the column to wrap text at, 0 disables wrapping
+	Width int
+	// ANSI enables terminal styling for emphasis, headings and code
+	ANSI bool
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiItalic = "\x1b[3m"
+	ansiCode   = "\x1b[36m"
+	ansiDim    = "\x1b[2m"
+)
+
+type block struct {
+	firstPrefix string
+	prefix      string
+	text        string
+	pre         bool
+	listItem    bool
+}
+
+// list is an open <ul> or <ol>, with the number of the next item when it
+// is ordered
+type list struct {
+	ordered bool
+	next    int
+}
+
+type renderer struct {
+	opts Options
+
+	blocks []block
+	inline strings.Builder
+
+	indent       string
+	bullet       string
+	bulletIndent string
+	lists        []list
+	pre          int
+	styles       []string
+
+	links     []string
+	linkIndex map[string]int
+}
+
+// Render converts an HTML fragment, such as a post description, into
+// wrapped plain text with links collected as numbered footnotes
+func Render(src string, opts Options) string {
+	r := &renderer{
+		opts:      opts,
+		linkIndex: map[string]int{},
+	}
+
+	if !strings.Contains(src, "<") {
+		// Plain text descriptions keep their paragraph breaks
+		for _, paragraph := range strings.Split(src, "\n\n") {
+			r.text(paragraph)
+			r.flush(false)
+		}
+		return r.String()
+	}
+
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return src
+	}
+	r.walk(doc)
+	r.flush(false)
+
+	return r.String()
+}
+
+func (r *renderer) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		r.walkChildren(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Head, atom.Script, atom.Style, atom.Noscript, atom.Iframe:
+		return
+
+	case atom.Br:
+		r.inline.WriteString("\n")
+
+	case atom.Hr:
+		r.flush(false)
+		r.inline.WriteString("----")
+		r.flush(false)
+
+	case atom.Img:
+		alt := strings.TrimSpace(attr(n, "alt"))
+		if alt == "" {
+			alt = "image"
+		} else {
+			alt = "image: " + alt
+		}
+		r.inline.WriteString("[" + alt + "]")
+		r.footnote(attr(n, "src"))
+
+	case atom.A:
+		r.walkChildren(n)
+		r.footnote(attr(n, "href"))
+
+	case atom.Em, atom.I, atom.Cite:
+		r.styled(n, ansiItalic, "_")
+
+	case atom.Strong, atom.B:
+		r.styled(n, ansiBold, "**")
+
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		if r.pre > 0 {
+			r.walkChildren(n)
+		} else {
+			r.styled(n, ansiCode, "`")
+		}
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		r.flush(false)
+		r.styled(n, ansiBold, "")
+		r.flush(false)
+
+	case atom.Ul, atom.Ol:
+		r.flush(false)
+		if r.bullet != "" {
+			// A list item that starts with a nested list still gets its
+			// own bullet, on a line of its own
+			r.blocks = append(r.blocks, block{
+				firstPrefix: r.bulletIndent + r.bullet,
+				prefix:      r.indent,
+				listItem:    true,
+			})
+			r.bullet = ""
+		}
+		l := list{ordered: n.DataAtom == atom.Ol, next: 1}
+		if start, err := strconv.Atoi(strings.TrimSpace(attr(n, "start"))); err == nil && l.ordered {
+			l.next = start
+		}
+		r.lists = append(r.lists, l)
+		r.walkChildren(n)
+		r.lists = r.lists[:len(r.lists)-1]
+		r.flush(false)
+
+	case atom.Li:
+		r.flush(false)
+		bullet := "• "
+		if len(r.lists) > 0 && r.lists[len(r.lists)-1].ordered {
+			l := &r.lists[len(r.lists)-1]
+			bullet = fmt.Sprintf("%d. ", l.next)
+			l.next++
+		}
+		saved := r.indent
+		r.bullet = bullet
+		r.bulletIndent = saved
+		r.indent = saved + strings.Repeat(" ", utf8.RuneCountInString(bullet))
+		r.walkChildren(n)
+		r.flush(true)
+		r.indent = saved
+
+	case atom.Blockquote:
+		r.flush(false)
+		saved := r.indent
+		r.indent = saved + "> "
+		r.walkChildren(n)
+		r.flush(false)
+		r.indent = saved
+
+	case atom.Pre:
+		r.flush(false)
+		r.pre++
+		r.walkChildren(n)
+		r.pre--
+		r.flushPre()
+
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer,
+		atom.Figure, atom.Figcaption, atom.Table, atom.Tr, atom.Dl, atom.Dt, atom.Dd:
+		r.flush(false)
+		r.walkChildren(n)
+		r.flush(false)
+
+	default:
+		r.walkChildren(n)
+	}
+}
+
+func (r *renderer) walkChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c)
+	}
+}
+
+func (r *renderer) text(s string) {
+	if r.pre > 0 {
+		r.inline.WriteString(s)
+		return
+	}
+	r.inline.WriteString(collapseSpace(s))
+}
+
+// styled renders the children of n with an ANSI style, or wraps them in
+// marker when ANSI output is disabled
+func (r *renderer) styled(n *html.Node, style, marker string) {
+	if !r.opts.ANSI {
+		r.inline.WriteString(marker)
+		r.walkChildren(n)
+		r.inline.WriteString(marker)
+		return
+	}
+
+	r.styles = append(r.styles, style)
+	r.inline.WriteString(style)
+	r.walkChildren(n)
+	r.styles = r.styles[:len(r.styles)-1]
+	r.inline.WriteString(ansiReset + strings.Join(r.styles, ""))
+}
+
+func (r *renderer) footnote(href string) {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+		return
+	}
+
+	idx, ok := r.linkIndex[href]
+	if !ok {
+		r.links = append(r.links, href)
+		idx = len(r.links)
+		r.linkIndex[href] = idx
+	}
+
+	ref := fmt.Sprintf("[%d]", idx)
+	if r.opts.ANSI {
+		ref = ansiDim + ref + ansiReset + strings.Join(r.styles, "")
+	}
+	r.inline.WriteString(ref)
+}
+
+func (r *renderer) flush(listItem bool) {
+	text := strings.TrimSpace(r.inline.String())
+	r.inline.Reset()
+	if visibleWidth(text) == 0 {
+		return
+	}
+
+	first := r.indent
+	if r.bullet != "" {
+		first = r.bulletIndent + r.bullet
+		r.bullet = ""
+		listItem = true
+	}
+
+	r.blocks = append(r.blocks, block{
+		firstPrefix: first,
+		prefix:      r.indent,
+		text:        text,
+		listItem:    listItem || len(r.lists) > 0,
+	})
+}
+
+func (r *renderer) flushPre() {
+	text := strings.Trim(r.inline.String(), "\n")
+	r.inline.Reset()
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	r.blocks = append(r.blocks, block{
+		firstPrefix: r.indent + "    ",
+		prefix:      r.indent + "    ",
+		text:        text,
+		pre:         true,
+	})
+}
+
+func (r *renderer) String() string {
+	var b strings.Builder
+	for i, blk := range r.blocks {
+		if i > 0 && !(blk.listItem && r.blocks[i-1].listItem) {
+			b.WriteString("\n")
+		}
+
+		var lines []string
+		if blk.pre {
+			lines = strings.Split(blk.text, "\n")
+		} else {
+			lines = wrap(blk.text, r.opts.Width-visibleWidth(blk.prefix))
+		}
+		for j, line := range lines {
+			prefix := blk.prefix
+			if j == 0 {
+				prefix = blk.firstPrefix
+			}
+			b.WriteString(strings.TrimRight(prefix+line, " ") + "\n")
+		}
+	}
+
+	if len(r.links) > 0 {
+		b.WriteString("\n")
+		for i, link := range r.links {
+			fmt.Fprintf(&b, "[%d] %s\n", i+1, link)
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// wrap breaks text into lines no wider than width, honouring explicit line
+// breaks. A width below 1 disables wrapping.
+func wrap(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		if width < 1 {
+			lines = append(lines, strings.Join(words, " "))
+			continue
+		}
+
+		line := words[0]
+		lineWidth := visibleWidth(line)
+		for _, word := range words[1:] {
+			wordWidth := visibleWidth(word)
+			if lineWidth+1+wordWidth > width {
+				lines = append(lines, line)
+				line, lineWidth = word, wordWidth
+				continue
+			}
+			line += " " + word
+			lineWidth += 1 + wordWidth
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// visibleWidth counts the runes of s that are displayed, skipping ANSI
+// escape sequences
+func visibleWidth(s string) int {
+	width := 0
+	inEscape := false
+	for _, c := range s {
+		switch {
+		case c == '\x1b':
+			inEscape = true
+		case inEscape:
+			if c >= '@' && c <= '~' && c != '[' {
+				inEscape = false
+			}
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+func collapseSpace(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			return " "
+		}
+		return ""
+	}
+
+	out := strings.Join(fields, " ")
+	first, _ := utf8.DecodeRuneInString(s)
+	if unicode.IsSpace(first) {
+		out = " " + out
+	}
+	last, _ := utf8.DecodeLastRuneInString(s)
+	if unicode.IsSpace(last) {
+		out += " "
+	}
+	return out
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
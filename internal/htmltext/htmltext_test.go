@@ -0,0 +1,38 @@
+package htmltext
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts Options
+		want string
+	}{
+		{"paragraphs", "<p>First paragraph.</p><p>Second   paragraph\nwith a break.</p>", Options{}, "First paragraph.\n\nSecond paragraph with a break."},
+		{"plain text", "plain text\n\nsecond", Options{}, "plain text\n\nsecond"},
+		{"wrapping", "<p>one two three four five six</p>", Options{Width: 10}, "one two\nthree four\nfive six"},
+		{"nested list", "<ul><li>one</li><li>two<ul><li>nested</li></ul></li></ul>", Options{}, "• one\n• two\n  • nested"},
+		{"nested list without leading text", "<ul><li><ul><li>a</li><li>b</li></ul></li><li>c</li></ul>", Options{}, "•\n  • a\n  • b\n• c"},
+		{"ordered list", "<ol><li>one</li><li>two</li></ol>", Options{}, "1. one\n2. two"},
+		{"ordered list start", `<ol start="4"><li>four</li><li>five</li></ol>`, Options{}, "4. four\n5. five"},
+		{"ordered list start zero", `<ol start="0"><li>zero</li></ol>`, Options{}, "0. zero"},
+		{"link footnotes", `<p>See <a href="https://a.example">this</a> and <a href="https://b.example">that</a> and <a href="https://a.example">again</a> <a href="#top">top</a>.</p>`, Options{}, "See this[1] and that[2] and again[1] top.\n\n[1] https://a.example\n[2] https://b.example"},
+		{"image", `<img src="https://a.example/cat.png" alt="a cat">`, Options{}, "[image: a cat][1]\n\n[1] https://a.example/cat.png"},
+		{"pre keeps whitespace", "<p>Code:</p><pre>  indented\n    more</pre>", Options{}, "Code:\n\n      indented\n        more"},
+		{"blockquote and script", "<blockquote>quoted</blockquote><script>x()</script>", Options{}, "> quoted"},
+		{"ANSI off", "<p><b>bold</b> <em>it</em> <code>x</code></p><h2>Head</h2>", Options{}, "**bold** _it_ `x`\n\nHead"},
+		{"ANSI on", "<p><b>bold</b> <em>it</em> <code>x</code></p><h2>Head</h2>", Options{ANSI: true}, "\x1b[1mbold\x1b[0m \x1b[3mit\x1b[0m \x1b[36mx\x1b[0m\n\n\x1b[1mHead\x1b[0m"},
+		{"ANSI footnote restores style", `<p><b>see <a href="https://a.example">a</a> now</b></p>`, Options{ANSI: true}, "\x1b[1msee a\x1b[2m[1]\x1b[0m\x1b[1m now\x1b[0m\n\n[1] https://a.example"},
+		{"ANSI wrapping ignores escapes", "<p><b>one two</b> three</p>", Options{Width: 7, ANSI: true}, "\x1b[1mone two\x1b[0m\nthree"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.src, tt.opts)
+			if got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
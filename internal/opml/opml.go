@@ -0,0 +1,131 @@
+package opml
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+)
+
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+	OwnerName   string `xml:"ownerName,omitempty"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Feed is a subscription found in an OPML document. Folder holds the names
+// of the enclosing outlines joined with "/", or is empty at the top level.
+type Feed struct {
+	Title   string
+	XMLURL  string
+	HTMLURL string
+	Folder  string
+}
+
+func Parse(r io.Reader) ([]Feed, error) {
+	doc := OPML{}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var feeds []Feed
+	collectFeeds(doc.Body.Outlines, nil, &feeds)
+	return feeds, nil
+}
+
+func collectFeeds(outlines []Outline, folders []string, feeds *[]Feed) {
+	for _, outline := range outlines {
+		name := strings.TrimSpace(outline.Title)
+		if name == "" {
+			name = strings.TrimSpace(outline.Text)
+		}
+
+		if outline.XMLURL == "" {
+			if name == "" {
+				collectFeeds(outline.Outlines, folders, feeds)
+			} else {
+				collectFeeds(outline.Outlines, append(folders, name), feeds)
+			}
+			continue
+		}
+
+		*feeds = append(*feeds, Feed{
+			Title:   name,
+			XMLURL:  strings.TrimSpace(outline.XMLURL),
+			HTMLURL: strings.TrimSpace(outline.HTMLURL),
+			Folder:  strings.Join(folders, "/"),
+		})
+	}
+}
+
+// Write encodes feeds as an OPML 2.0 document, nesting each feed in
+// outlines for its folder path
+func Write(w io.Writer, title string, feeds []Feed) error {
+	doc := OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: time.Now().Format(time.RFC1123Z),
+		},
+	}
+
+	root := &Outline{}
+	for _, feed := range feeds {
+		parent := root
+		if feed.Folder != "" {
+			for _, name := range strings.Split(feed.Folder, "/") {
+				parent = folderOutline(parent, name)
+			}
+		}
+
+		parent.Outlines = append(parent.Outlines, Outline{
+			Text:    feed.Title,
+			Title:   feed.Title,
+			Type:    "rss",
+			XMLURL:  feed.XMLURL,
+			HTMLURL: feed.HTMLURL,
+		})
+	}
+	doc.Body.Outlines = root.Outlines
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func folderOutline(parent *Outline, name string) *Outline {
+	for i := range parent.Outlines {
+		if parent.Outlines[i].XMLURL == "" && parent.Outlines[i].Text == name {
+			return &parent.Outlines[i]
+		}
+	}
+	parent.Outlines = append(parent.Outlines, Outline{Text: name, Title: name})
+	return &parent.Outlines[len(parent.Outlines)-1]
+}
@@ -0,0 +1,178 @@
+package podcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const partSuffix = ".part"
+
+// Download fetches mediaURL into dest. Data is written to a ".part" file
+// first so an interrupted download resumes with a range request next time.
+func Download(ctx context.Context, mediaURL, dest string) error {
+	partPath := dest + partSuffix
+
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", mediaURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("User-Agent", "gator")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The server ignored the range, start over
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file may already hold the whole episode, but only
+		// trust it if the server's size agrees. Otherwise start over.
+		if total, ok := rangeTotal(res.Header.Get("Content-Range")); ok && total == offset {
+			return os.Rename(partPath, dest)
+		}
+		res.Body.Close()
+		if err := os.Remove(partPath); err != nil {
+			return err
+		}
+		return Download(ctx, mediaURL, dest)
+	default:
+		return fmt.Errorf("could not download %v: %v", mediaURL, res.Status)
+	}
+
+	f, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, res.Body); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(partPath, dest)
+}
+
+// rangeTotal reads the complete length from a "bytes */<total>" or
+// "bytes <first>-<last>/<total>" Content-Range header
+func rangeTotal(contentRange string) (int64, bool) {
+	_, total, ok := strings.Cut(contentRange, "/")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(total), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// FeedDir names the directory a feed's episodes are saved in. It is the
+// feed's id alone, so it stays unique and doesn't move when the feed is
+// renamed.
+func FeedDir(feedID string) string {
+	return feedID
+}
+
+// Filename builds a stable, filesystem safe name for an episode from its
+// publish date, title and media URL or type
+func Filename(title string, published time.Time, mediaURL, mediaType string) string {
+	name := SafeName(title)
+	if name == "" {
+		name = "episode"
+	}
+	if !published.IsZero() {
+		name = published.Format("2006-01-02") + "-" + name
+	}
+	return name + extension(mediaURL, mediaType)
+}
+
+// SafeName replaces everything but letters, digits, dots, dashes and
+// underscores so s can be used as a file or directory name
+func SafeName(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, c := range s {
+		if c < 128 && (c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '_') {
+			b.WriteRune(c)
+			dash = false
+			continue
+		}
+		if !dash {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	name := strings.Trim(b.String(), "-.")
+	if len(name) > 80 {
+		name = strings.TrimRight(name[:80], "-.")
+	}
+	return name
+}
+
+func extension(mediaURL, mediaType string) string {
+	if u, err := url.Parse(mediaURL); err == nil {
+		if ext := path.Ext(u.Path); ext != "" && len(ext) <= 5 {
+			return strings.ToLower(ext)
+		}
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
+
+// Prune deletes downloaded and partial episodes in dir whose names are not
+// in keep, returning the names of the removed files
+func Prune(dir string, keep map[string]bool) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if keep[strings.TrimSuffix(entry.Name(), partSuffix)] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return removed, err
+		}
+		removed = append(removed, entry.Name())
+	}
+	return removed, nil
+}
@@ -0,0 +1,313 @@
+package publish
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+)
+
+var ErrUnknownFormat = errors.New("unknown feed format, expected rss or atom")
+
+// ErrUnauthorized is returned by a Handler's load function when the
+// request's credentials are missing or wrong
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Feed is a list of posts to publish. Link is the page the feed describes
+// and Self the URL the feed itself is served from, if known.
+type Feed struct {
+	ID          string
+	Title       string
+	Link        string
+	Self        string
+	Description string
+	Author      string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item is one published post. Description is a short summary and Content
+// the full HTML, either may be empty.
+type Item struct {
+	ID          string
+	Title       string
+	Link        string
+	Description string
+	Content     string
+	Author      string
+	Categories  []string
+	SourceName  string
+	SourceURL   string
+	Published   time.Time
+}
+
+// ContentType returns the Content-Type header for documents in format
+func ContentType(format string) string {
+	return mediaType(format) + "; charset=utf-8"
+}
+
+func mediaType(format string) string {
+	if format == FormatAtom {
+		return "application/atom+xml"
+	}
+	return "application/rss+xml"
+}
+
+// Write encodes feed as an RSS 2.0 or Atom 1.0 document
+func Write(w io.Writer, format string, feed Feed) error {
+	var doc any
+	switch format {
+	case FormatRSS:
+		doc = rssDocument(feed)
+	case FormatAtom:
+		doc = atomDocument(feed)
+	default:
+		return ErrUnknownFormat
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Handler serves the feed returned by load. The format is taken from the
+// "format" query parameter and defaults to RSS. Load errors wrapping
+// ErrUnauthorized are answered with 401.
+func Handler(load func(r *http.Request) (Feed, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = FormatRSS
+		}
+		if format != FormatRSS && format != FormatAtom {
+			http.Error(w, ErrUnknownFormat.Error(), http.StatusBadRequest)
+			return
+		}
+
+		feed, err := load(r)
+		if errors.Is(err, ErrUnauthorized) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			log.Printf("could not load feed for %v: %v", r.URL.Path, err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		// Encode first so a failure can still be reported as an error
+		// instead of a truncated document
+		var buf bytes.Buffer
+		if err := Write(&buf, format, feed); err != nil {
+			log.Printf("could not encode feed for %v: %v", r.URL.Path, err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType(format))
+		w.Write(buf.Bytes())
+	})
+}
+
+type rssRoot struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Content string     `xml:"xmlns:content,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Self          *atomLink `xml:"atom:link,omitempty"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Generator     string    `xml:"generator"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string     `xml:"title"`
+	Link        string     `xml:"link,omitempty"`
+	GUID        rssGUID    `xml:"guid"`
+	Description string     `xml:"description,omitempty"`
+	Content     *cdata     `xml:"content:encoded,omitempty"`
+	Author      string     `xml:"dc:creator,omitempty"`
+	Categories  []string   `xml:"category"`
+	Source      *rssSource `xml:"source,omitempty"`
+	PubDate     string     `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssSource struct {
+	URL   string `xml:"url,attr"`
+	Value string `xml:",chardata"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+func rssDocument(feed Feed) rssRoot {
+	channel := rssChannel{
+		Title:       feed.Title,
+		Link:        feed.Link,
+		Description: feed.Description,
+		Generator:   "gator",
+	}
+	if feed.Self != "" {
+		channel.Self = &atomLink{Href: feed.Self, Rel: "self", Type: mediaType(FormatRSS)}
+	}
+	if !feed.Updated.IsZero() {
+		channel.LastBuildDate = feed.Updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, item := range feed.Items {
+		entry := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: item.ID},
+			Description: item.Description,
+			Author:      item.Author,
+			Categories:  item.Categories,
+		}
+		if item.Content != "" {
+			entry.Content = &cdata{Value: item.Content}
+		}
+		if item.SourceName != "" && item.SourceURL != "" {
+			entry.Source = &rssSource{URL: item.SourceURL, Value: item.SourceName}
+		}
+		if !item.Published.IsZero() {
+			entry.PubDate = item.Published.UTC().Format(time.RFC1123Z)
+		}
+		channel.Items = append(channel.Items, entry)
+	}
+
+	return rssRoot{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Content: "http://purl.org/rss/1.0/modules/content/",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Channel: channel,
+	}
+}
+
+type atomRoot struct {
+	XMLName   xml.Name    `xml:"feed"`
+	NS        string      `xml:"xmlns,attr"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Subtitle  string      `xml:"subtitle,omitempty"`
+	Updated   string      `xml:"updated"`
+	Author    atomAuthor  `xml:"author"`
+	Generator string      `xml:"generator"`
+	Links     []atomLink  `xml:"link"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      atomText       `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+func atomDocument(feed Feed) atomRoot {
+	updated := feed.Updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	root := atomRoot{
+		NS:        "http://www.w3.org/2005/Atom",
+		ID:        feed.ID,
+		Title:     feed.Title,
+		Subtitle:  feed.Description,
+		Updated:   updated.UTC().Format(time.RFC3339),
+		Author:    atomAuthor{Name: feed.Author},
+		Generator: "gator",
+	}
+	if feed.Link != "" {
+		root.Links = append(root.Links, atomLink{Href: feed.Link, Rel: "alternate"})
+	}
+	if feed.Self != "" {
+		root.Links = append(root.Links, atomLink{Href: feed.Self, Rel: "self", Type: mediaType(FormatAtom)})
+	}
+
+	for _, item := range feed.Items {
+		published := item.Published
+		if published.IsZero() {
+			published = updated
+		}
+
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     atomText{Type: "text", Value: item.Title},
+			Updated:   published.UTC().Format(time.RFC3339),
+			Published: published.UTC().Format(time.RFC3339),
+		}
+		if item.Author != "" {
+			entry.Author = &atomAuthor{Name: item.Author}
+		}
+		if item.Link != "" {
+			entry.Links = append(entry.Links, atomLink{Href: item.Link, Rel: "alternate"})
+		}
+		if item.Description != "" {
+			entry.Summary = &atomText{Type: "html", Value: item.Description}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Value: item.Content}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		root.Entries = append(root.Entries, entry)
+	}
+
+	return root
+}
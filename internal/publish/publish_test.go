@@ -0,0 +1,52 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	feed := Feed{ID: "urn:test", Title: "Test & feed", Items: []Item{{ID: "urn:item", Title: "One"}}}
+
+	tests := []struct {
+		name       string
+		query      string
+		loadErr    error
+		wantStatus int
+		wantBody   string
+	}{
+		{"rss", "", nil, http.StatusOK, "<rss"},
+		{"atom", "?format=atom", nil, http.StatusOK, "<feed"},
+		{"unknown format", "?format=json", nil, http.StatusBadRequest, ErrUnknownFormat.Error()},
+		{"unauthorized", "", fmt.Errorf("%w: missing API key", ErrUnauthorized), http.StatusUnauthorized, "missing API key"},
+		{"load error is not exposed", "", errors.New("pq: connection refused"), http.StatusInternalServerError, "internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Handler(func(r *http.Request) (Feed, error) {
+				return feed, tt.loadErr
+			})
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", "/timeline.xml"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			body := rec.Body.String()
+			if !strings.Contains(body, tt.wantBody) {
+				t.Errorf("body %q does not contain %q", body, tt.wantBody)
+			}
+			if strings.Contains(body, "connection refused") {
+				t.Error("body exposes the load error")
+			}
+			if tt.wantStatus == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without a WWW-Authenticate header")
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package rss
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	weekdayPrefix = regexp.MustCompile(`(?i)^(mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?,?\s*`)
+	monthName     = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?`)
+	zoneSuffix    = regexp.MustCompile(`\s([A-Za-z]{1,5})$`)
+	comment       = regexp.MustCompile(`\s*\([^)]*\)`)
+)
+
+// zoneOffsets maps the zone abbreviations seen in feeds to numeric offsets,
+// since time.Parse treats unknown abbreviations as UTC
+var zoneOffsets = map[string]string{
+	"UT":   "+0000",
+	"UTC":  "+0000",
+	"GMT":  "+0000",
+	"Z":    "+0000",
+	"EST":  "-0500",
+	"EDT":  "-0400",
+	"CST":  "-0600",
+	"CDT":  "-0500",
+	"MST":  "-0700",
+	"MDT":  "-0600",
+	"PST":  "-0800",
+	"PDT":  "-0700",
+	"AKST": "-0900",
+	"AKDT": "-0800",
+	"HST":  "-1000",
+	"BST":  "+0100",
+	"IST":  "+0530",
+	"WET":  "+0000",
+	"WEST": "+0100",
+	"CET":  "+0100",
+	"CEST": "+0200",
+	"EET":  "+0200",
+	"EEST": "+0300",
+	"MSK":  "+0300",
+	"JST":  "+0900",
+	"KST":  "+0900",
+	"AEST": "+1000",
+	"AEDT": "+1100",
+	"ACST": "+0930",
+	"AWST": "+0800",
+	"NZST": "+1200",
+	"NZDT": "+1300",
+}
+
+// dateLayouts are tried in order after the input has been normalised, so
+// they don't need weekday names, long month names or zone abbreviations
+var dateLayouts = []string{
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 -07:00",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 Jan 06 15:04:05 MST",
+	"2 Jan 06 15:04:05",
+	"Jan 2, 2006 15:04:05 -0700",
+	"Jan 2, 2006 15:04 -0700",
+	"Jan 2, 2006",
+	"Jan 2 15:04:05 2006",
+	time.RFC3339,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseDate parses the publish dates found in RSS and Atom feeds. It covers
+// the RFC 822/1123 family with numeric or named zones, two digit years and
+// RFC 3339 with fractional seconds, and reports false when s can't be read.
+func ParseDate(s string) (time.Time, bool) {
+	s = normalizeDate(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		if t.Year() < 1900 {
+			return time.Time{}, false
+		}
+		return t.UTC(), true
+	}
+
+	return time.Time{}, false
+}
+
+func normalizeDate(s string) string {
+	s = comment.ReplaceAllString(s, "")
+	s = strings.Join(strings.Fields(s), " ")
+	s = weekdayPrefix.ReplaceAllString(s, "")
+	s = monthName.ReplaceAllStringFunc(s, func(m string) string {
+		return m[:3]
+	})
+
+	if m := zoneSuffix.FindStringSubmatch(s); m != nil {
+		if offset, ok := zoneOffsets[strings.ToUpper(m[1])]; ok {
+			s = s[:len(s)-len(m[1])] + offset
+		}
+	}
+
+	return s
+}
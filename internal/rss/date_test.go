@@ -0,0 +1,94 @@
+package rss
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  time.Time
+	}{
+		{"RFC 1123 numeric zone", "Mon, 02 Jan 2006 15:04:05 -0700", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{"RFC 1123 GMT", "Mon, 02 Jan 2006 15:04:05 GMT", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"RFC 822 two digit year", "02 Jan 06 15:04:05 +0000", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"single digit day", "Tue, 5 Mar 2024 08:00:00 +0100", time.Date(2024, 3, 5, 7, 0, 0, 0, time.UTC)},
+		{"named zone EST", "Wed, 10 Jul 2024 09:30:00 EST", time.Date(2024, 7, 10, 14, 30, 0, 0, time.UTC)},
+		{"named zone PDT", "Wed, 10 Jul 2024 09:30:00 PDT", time.Date(2024, 7, 10, 16, 30, 0, 0, time.UTC)},
+		{"named zone CEST", "Wed, 10 Jul 2024 09:30:00 CEST", time.Date(2024, 7, 10, 7, 30, 0, 0, time.UTC)},
+		{"lower case zone", "Wed, 10 Jul 2024 09:30:00 utc", time.Date(2024, 7, 10, 9, 30, 0, 0, time.UTC)},
+		{"missing seconds", "Thu, 11 Jul 2024 18:45 +0000", time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)},
+		{"colon in offset", "11 Jul 2024 18:45:00 +02:00", time.Date(2024, 7, 11, 16, 45, 0, 0, time.UTC)},
+		{"no zone", "11 Jul 2024 18:45:00", time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)},
+		{"date only", "11 Jul 2024", time.Date(2024, 7, 11, 0, 0, 0, 0, time.UTC)},
+		{"long weekday and month", "Thursday, 11 July 2024 18:45:00 GMT", time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)},
+		{"weekday without comma", "Thu 11 Jul 2024 18:45:00 GMT", time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)},
+		{"abbreviated month with dot", "11 Sept. 2024 18:45:00 GMT", time.Date(2024, 9, 11, 18, 45, 0, 0, time.UTC)},
+		{"lower case month", "11 jul 2024 18:45:00 GMT", time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)},
+		{"extra whitespace", "  Thu,  11 Jul 2024\n 18:45:00  GMT ", time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)},
+		{"zone comment", "Thu, 11 Jul 2024 18:45:00 -0400 (EDT)", time.Date(2024, 7, 11, 22, 45, 0, 0, time.UTC)},
+		{"US style", "Jul 11, 2024", time.Date(2024, 7, 11, 0, 0, 0, 0, time.UTC)},
+		{"US style with time", "July 11, 2024 18:45:00 +0000", time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)},
+		{"ctime", "Thu Jul 11 18:45:00 2024", time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)},
+		{"RFC 3339 UTC", "2024-07-11T18:45:00Z", time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)},
+		{"RFC 3339 offset", "2024-07-11T18:45:00+02:00", time.Date(2024, 7, 11, 16, 45, 0, 0, time.UTC)},
+		{"RFC 3339 fractional seconds", "2024-07-11T18:45:00.123456Z", time.Date(2024, 7, 11, 18, 45, 0, 123456000, time.UTC)},
+		{"ISO 8601 offset without colon", "2024-07-11T18:45:00-0500", time.Date(2024, 7, 11, 23, 45, 0, 0, time.UTC)},
+		{"ISO 8601 no zone", "2024-07-11T18:45:00", time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)},
+		{"ISO 8601 missing seconds", "2024-07-11T18:45Z", time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)},
+		{"ISO 8601 with space", "2024-07-11 18:45:00", time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)},
+		{"ISO 8601 date only", "2024-07-11", time.Date(2024, 7, 11, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseDate(tt.input)
+			if !ok {
+				t.Fatalf("ParseDate(%q) failed", tt.input)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("ParseDate(%q) returned location %v, want UTC", tt.input, got.Location())
+			}
+		})
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"   ",
+		"yesterday",
+		"not a date at all",
+		"32 Jan 2024",
+		"2024-13-01",
+		"0001-01-01T00:00:00Z",
+	} {
+		if got, ok := ParseDate(input); ok {
+			t.Errorf("ParseDate(%q) = %v, want failure", input, got)
+		}
+	}
+}
+
+func TestNormalizeDate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Mon, 02 Jan 2006 15:04:05 GMT", "02 Jan 2006 15:04:05 +0000"},
+		{"Monday, 02 January 2006 15:04:05 EST", "02 Jan 2006 15:04:05 -0500"},
+		{"02 Jan 2006 15:04:05 -0700 (MST)", "02 Jan 2006 15:04:05 -0700"},
+		{"02  Jan\t2006", "02 Jan 2006"},
+		{"02 Jan 2006 15:04:05 XYZ", "02 Jan 2006 15:04:05 XYZ"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeDate(tt.input); got != tt.want {
+			t.Errorf("normalizeDate(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
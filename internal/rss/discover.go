@@ -0,0 +1,123 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type FeedLink struct {
+	URL   string
+	Title string
+	Type  string
+}
+
+var feedTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+}
+
+// commonFeedPaths are probed when a page doesn't advertise any feeds
+var commonFeedPaths = []string{
+	"/feed",
+	"/rss",
+	"/index.xml",
+	"/feed.xml",
+	"/rss.xml",
+	"/atom.xml",
+	"/feed.json",
+}
+
+// Discover finds the feeds offered by a website. pageURL may itself be a
+// feed, otherwise the page's <link rel="alternate"> tags are used, falling
+// back to probing common feed paths on the same host.
+func Discover(ctx context.Context, pageURL string) ([]FeedLink, error) {
+	body, finalURL, err := fetch(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if format, err := feedFormat(body); err == nil {
+		return []FeedLink{{URL: finalURL.String(), Type: format}}, nil
+	}
+
+	links := alternateFeedLinks(body, finalURL)
+	if len(links) > 0 {
+		return links, nil
+	}
+
+	for _, path := range commonFeedPaths {
+		candidate := finalURL.ResolveReference(&url.URL{Path: path})
+		feedBody, feedURL, err := fetch(ctx, candidate.String())
+		if err != nil {
+			continue
+		}
+		format, err := feedFormat(feedBody)
+		if err != nil {
+			continue
+		}
+		links = append(links, FeedLink{URL: feedURL.String(), Type: format})
+		break
+	}
+
+	return links, nil
+}
+
+func alternateFeedLinks(body []byte, base *url.URL) []FeedLink {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	var links []FeedLink
+	seen := map[string]bool{}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Link {
+			link := FeedLink{}
+			rel := ""
+			for _, a := range n.Attr {
+				switch strings.ToLower(a.Key) {
+				case "rel":
+					rel = strings.ToLower(a.Val)
+				case "type":
+					link.Type = strings.ToLower(strings.TrimSpace(a.Val))
+				case "href":
+					link.URL = strings.TrimSpace(a.Val)
+				case "title":
+					link.Title = strings.TrimSpace(a.Val)
+				}
+			}
+
+			href, err := base.Parse(link.URL)
+			if err == nil && link.URL != "" && feedTypes[link.Type] && hasToken(rel, "alternate") {
+				link.URL = href.String()
+				if !seen[link.URL] {
+					seen[link.URL] = true
+					links = append(links, link)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return links
+}
+
+func hasToken(list, token string) bool {
+	for _, field := range strings.Fields(list) {
+		if field == token {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,275 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"html"
+	"strconv"
+	"strings"
+)
+
+const (
+	formatRSS  = "rss"
+	formatRDF  = "rdf"
+	formatAtom = "atom"
+	formatJSON = "json"
+)
+
+var ErrNotFeed = errors.New("document is not an RSS, Atom or JSON feed")
+
+// feedFormat looks at the root of a document to tell which parser it needs
+func feedFormat(body []byte) (string, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return formatJSON, nil
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", ErrNotFeed
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(start.Name.Local) {
+		case "rss":
+			return formatRSS, nil
+		case "rdf":
+			return formatRDF, nil
+		case "feed":
+			return formatAtom, nil
+		default:
+			return "", ErrNotFeed
+		}
+	}
+}
+
+// rdfFeed is an RSS 1.0 document, where items and the image are siblings
+// of the channel rather than inside it
+type rdfFeed struct {
+	Image RSSImage  `xml:"image"`
+	Items []RSSItem `xml:"item"`
+}
+
+func parseRDF(body []byte) (*RSSFeed, error) {
+	rssFeed := &RSSFeed{}
+	if err := xml.Unmarshal(body, rssFeed); err != nil {
+		return nil, err
+	}
+	rssFeed.setChannelLink()
+
+	feed := rdfFeed{}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, err
+	}
+	rssFeed.Channel.Item = append(rssFeed.Channel.Item, feed.Items...)
+	if rssFeed.Channel.Image.URL == "" {
+		rssFeed.Channel.Image = feed.Image
+	}
+
+	return rssFeed, nil
+}
+
+type atomFeed struct {
+	Title    atomText    `xml:"title"`
+	Subtitle atomText    `xml:"subtitle"`
+	Icon     string      `xml:"icon"`
+	Logo     string      `xml:"logo"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title      atomText       `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Summary    atomText       `xml:"summary"`
+	Content    atomText       `xml:"content"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Authors    []atomAuthor   `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// String returns the text content, keeping the markup of xhtml content
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// Plain returns the text for places that show it as plain text, such as
+// titles. Entities in html text are still escaped once the XML is decoded.
+func (t atomText) Plain() string {
+	if t.Type == "html" {
+		return html.UnescapeString(t.String())
+	}
+	return t.String()
+}
+
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+func parseAtom(body []byte) (*RSSFeed, error) {
+	feed := atomFeed{}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, err
+	}
+
+	rssFeed := &RSSFeed{}
+	rssFeed.Channel.Title = feed.Title.Plain()
+	rssFeed.Channel.Link = alternateLink(feed.Links)
+	rssFeed.Channel.Description = feed.Subtitle.Plain()
+	rssFeed.Channel.Image.URL = feed.Logo
+	if rssFeed.Channel.Image.URL == "" {
+		rssFeed.Channel.Image.URL = feed.Icon
+	}
+
+	for _, entry := range feed.Entries {
+		item := RSSItem{
+			Title:       entry.Title.Plain(),
+			Link:        alternateLink(entry.Links),
+			Description: entry.Summary.String(),
+			Content:     entry.Content.String(),
+			PubDate:     entry.Published,
+		}
+		if item.PubDate == "" {
+			item.PubDate = entry.Updated
+		}
+		if item.Description == "" {
+			item.Description = item.Content
+		}
+		if len(entry.Authors) > 0 {
+			item.Author = strings.TrimSpace(entry.Authors[0].Name)
+		}
+		for _, category := range entry.Categories {
+			item.Categories = append(item.Categories, strings.TrimSpace(category.Term))
+		}
+		for _, link := range entry.Links {
+			if link.Rel == "enclosure" {
+				item.Enclosures = append(item.Enclosures, RSSEnclosure{URL: link.Href, Type: link.Type, Length: link.Length})
+			}
+		}
+		rssFeed.Channel.Item = append(rssFeed.Channel.Item, item)
+	}
+
+	return rssFeed, nil
+}
+
+type jsonFeed struct {
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Icon        string         `json:"icon"`
+	Favicon     string         `json:"favicon"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Author        *jsonFeedAuthor      `json:"author"`
+	Authors       []jsonFeedAuthor     `json:"authors"`
+	Tags          []string             `json:"tags"`
+	Attachments   []jsonFeedAttachment `json:"attachments"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+}
+
+func parseJSONFeed(body []byte) (*RSSFeed, error) {
+	feed := jsonFeed{}
+	if err := json.Unmarshal(body, &feed); err != nil {
+		return nil, err
+	}
+
+	rssFeed := &RSSFeed{}
+	rssFeed.Channel.Title = feed.Title
+	rssFeed.Channel.Link = feed.HomePageURL
+	rssFeed.Channel.Description = feed.Description
+	rssFeed.Channel.Image.URL = feed.Icon
+	if rssFeed.Channel.Image.URL == "" {
+		rssFeed.Channel.Image.URL = feed.Favicon
+	}
+
+	for _, feedItem := range feed.Items {
+		item := RSSItem{
+			Title:       feedItem.Title,
+			Link:        feedItem.URL,
+			Description: feedItem.Summary,
+			Content:     feedItem.ContentHTML,
+			PubDate:     feedItem.DatePublished,
+			Categories:  feedItem.Tags,
+		}
+		if item.Link == "" {
+			item.Link = feedItem.ID
+		}
+		if item.Description == "" {
+			item.Description = feedItem.ContentText
+		}
+		if item.PubDate == "" {
+			item.PubDate = feedItem.DateModified
+		}
+		if len(feedItem.Authors) > 0 {
+			item.Author = feedItem.Authors[0].Name
+		} else if feedItem.Author != nil {
+			item.Author = feedItem.Author.Name
+		}
+		for _, attachment := range feedItem.Attachments {
+			enclosure := RSSEnclosure{URL: attachment.URL, Type: attachment.MimeType}
+			if attachment.SizeInBytes > 0 {
+				enclosure.Length = strconv.FormatInt(attachment.SizeInBytes, 10)
+			}
+			item.Enclosures = append(item.Enclosures, enclosure)
+		}
+		rssFeed.Channel.Item = append(rssFeed.Channel.Item, item)
+	}
+
+	return rssFeed, nil
+}
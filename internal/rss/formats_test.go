@@ -0,0 +1,96 @@
+package rss
+
+import "testing"
+
+const rdfDocument = `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="http://example.com/">
+    <title>Example</title>
+    <link>http://example.com/</link>
+    <description>An RSS 1.0 feed</description>
+  </channel>
+  <image rdf:about="http://example.com/logo.png">
+    <url>http://example.com/logo.png</url>
+  </image>
+  <item rdf:about="http://example.com/1">
+    <title>First</title>
+    <link>http://example.com/1</link>
+    <dc:date>2024-07-11T18:45:00Z</dc:date>
+    <dc:creator>Ann</dc:creator>
+  </item>
+  <item rdf:about="http://example.com/2">
+    <title>Second</title>
+    <link>http://example.com/2</link>
+  </item>
+</rdf:RDF>`
+
+func TestParseFeedRDF(t *testing.T) {
+	feed, err := ParseFeed([]byte(rdfDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if feed.Channel.Title != "Example" || feed.Channel.Link != "http://example.com/" {
+		t.Errorf("channel = %q %q, want Example http://example.com/", feed.Channel.Title, feed.Channel.Link)
+	}
+	if got := feed.ImageURL(); got != "http://example.com/logo.png" {
+		t.Errorf("ImageURL() = %q", got)
+	}
+	if len(feed.Channel.Item) != 2 {
+		t.Fatalf("got %d items, want 2", len(feed.Channel.Item))
+	}
+
+	first := feed.Channel.Item[0]
+	if first.Title != "First" || first.Link != "http://example.com/1" {
+		t.Errorf("first item = %q %q", first.Title, first.Link)
+	}
+	if first.PubDate != "2024-07-11T18:45:00Z" {
+		t.Errorf("first item PubDate = %q, want the dc:date", first.PubDate)
+	}
+	if first.AuthorName() != "Ann" {
+		t.Errorf("first item AuthorName() = %q, want Ann", first.AuthorName())
+	}
+}
+
+const atomDocument = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title type="html">Fish &amp;amp; chips</title>
+  <link href="http://example.com/"/>
+  <entry>
+    <title>Escaping &amp;lt;b&amp;gt;</title>
+    <link href="http://example.com/1"/>
+    <summary type="text">Write &amp;lt; for a less-than sign</summary>
+    <content type="html">&lt;p&gt;Use &amp;amp;lt; in HTML&lt;/p&gt;</content>
+    <updated>2024-07-11T18:45:00Z</updated>
+  </entry>
+</feed>`
+
+func TestParseFeedAtomEscaping(t *testing.T) {
+	feed, err := ParseFeed([]byte(atomDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if feed.Channel.Title != "Fish & chips" {
+		t.Errorf("channel title = %q", feed.Channel.Title)
+	}
+	if len(feed.Channel.Item) != 1 {
+		t.Fatalf("got %d items, want 1", len(feed.Channel.Item))
+	}
+
+	item := feed.Channel.Item[0]
+	tests := []struct {
+		field string
+		got   string
+		want  string
+	}{
+		{"title", item.Title, "Escaping &lt;b&gt;"},
+		{"description", item.Description, "Write &lt; for a less-than sign"},
+		{"content", item.Content, "<p>Use &amp;lt; in HTML</p>"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%v = %q, want %q", tt.field, tt.got, tt.want)
+		}
+	}
+}
@@ -3,53 +3,224 @@ package rss
 import (
 	"context"
 	"encoding/xml"
+	"fmt"
 	"html"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		Title       string      `xml:"title"`
+		Link        string      `xml:"-"`
+		Links       []RSSLink   `xml:"link"`
+		Description string      `xml:"description"`
+		ITunesImage ITunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+		Image       RSSImage    `xml:"image"`
+		Item        []RSSItem   `xml:"item"`
 	} `xml:"channel"`
 }
 
+// RSSLink also matches namespaced links such as <atom:link rel="self">,
+// which is why the channel link is picked out after unmarshalling
+type RSSLink struct {
+	XMLName xml.Name
+	Text    string `xml:",chardata"`
+}
+
+type RSSImage struct {
+	URL string `xml:"url"`
+}
+
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string         `xml:"title"`
+	Link        string         `xml:"link"`
+	Description string         `xml:"description"`
+	PubDate     string         `xml:"pubDate"`
+	Content     string         `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Author      string         `xml:"author"`
+	Creator     string         `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	DCDate      string         `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Categories  []string       `xml:"category"`
+	Comments    string         `xml:"comments"`
+	Enclosures  []RSSEnclosure `xml:"enclosure"`
+
+	ITunesDuration string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ITunesEpisode  string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode"`
+	ITunesSeason   string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd season"`
+	ITunesImage    ITunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+}
+
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// ImageURL returns the channel image, falling back to the site's favicon
+func (rssFeed *RSSFeed) ImageURL() string {
+	if rssFeed.Channel.Image.URL != "" {
+		return strings.TrimSpace(rssFeed.Channel.Image.URL)
+	}
+	if rssFeed.Channel.ITunesImage.Href != "" {
+		return strings.TrimSpace(rssFeed.Channel.ITunesImage.Href)
+	}
+
+	site, err := url.Parse(rssFeed.Channel.Link)
+	if err != nil || site.Host == "" {
+		return ""
+	}
+	return site.Scheme + "://" + site.Host + "/favicon.ico"
+}
+
+// AuthorName returns the item's author, falling back to dc:creator
+func (item RSSItem) AuthorName() string {
+	if item.Author != "" {
+		return item.Author
+	}
+	return item.Creator
+}
+
+// Duration parses itunes:duration, which feeds give either as a number of
+// seconds or as [HH:]MM:SS
+func (item RSSItem) Duration() (time.Duration, bool) {
+	value := strings.TrimSpace(item.ITunesDuration)
+	if value == "" {
+		return 0, false
+	}
+
+	seconds := 0
+	for _, part := range strings.Split(value, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+func (item RSSItem) Episode() (int32, bool) {
+	return parsePositiveInt(item.ITunesEpisode)
+}
+
+func (item RSSItem) Season() (int32, bool) {
+	return parsePositiveInt(item.ITunesSeason)
+}
+
+func parsePositiveInt(s string) (int32, bool) {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return int32(n), true
+}
+
+// Size returns the enclosure length in bytes, or false when the feed did
+// not provide a usable value
+func (enclosure RSSEnclosure) Size() (int64, bool) {
+	length, err := strconv.ParseInt(strings.TrimSpace(enclosure.Length), 10, 64)
+	if err != nil || length <= 0 {
+		return 0, false
+	}
+	return length, true
 }
 
 func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	body, _, err := fetch(ctx, feedURL)
 	if err != nil {
 		return &RSSFeed{}, err
 	}
-	req.Header.Add("User-Agent", "gator")
 
-	cli := &http.Client{}
-	res, err := cli.Do(req)
+	return ParseFeed(body)
+}
+
+// ParseFeed reads an RSS, Atom or JSON Feed document into an RSSFeed
+func ParseFeed(body []byte) (*RSSFeed, error) {
+	format, err := feedFormat(body)
 	if err != nil {
 		return &RSSFeed{}, err
 	}
 
-	body, err := io.ReadAll(res.Body)
+	rssFeed := &RSSFeed{}
+	switch format {
+	case formatAtom:
+		rssFeed, err = parseAtom(body)
+	case formatJSON:
+		rssFeed, err = parseJSONFeed(body)
+	case formatRDF:
+		rssFeed, err = parseRDF(body)
+	default:
+		err = xml.Unmarshal(body, rssFeed)
+		rssFeed.setChannelLink()
+	}
 	if err != nil {
 		return &RSSFeed{}, err
 	}
+	// RSS 1.0 and some RSS 2.0 feeds date items with Dublin Core instead
+	for i := range rssFeed.Channel.Item {
+		if rssFeed.Channel.Item[i].PubDate == "" {
+			rssFeed.Channel.Item[i].PubDate = rssFeed.Channel.Item[i].DCDate
+		}
+	}
+	// Atom says how each text is encoded, so parseAtom decodes it itself.
+	// RSS gives no hint and titles are often escaped twice.
+	if format != formatAtom {
+		rssFeed.unescapeString()
+	}
+
+	return rssFeed, nil
+}
+
+// rss1Namespace is the default namespace of RSS 1.0 documents
+const rss1Namespace = "http://purl.org/rss/1.0/"
+
+// setChannelLink picks the channel's own <link> out of the links it
+// declares, skipping namespaced ones such as <atom:link>
+func (f *RSSFeed) setChannelLink() {
+	for _, link := range f.Channel.Links {
+		space := link.XMLName.Space
+		if (space == "" || space == rss1Namespace) && strings.TrimSpace(link.Text) != "" {
+			f.Channel.Link = strings.TrimSpace(link.Text)
+			break
+		}
+	}
+}
+
+// fetch GETs a URL and returns the body along with the final URL after
+// redirects
+func fetch(ctx context.Context, rawURL string) ([]byte, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Add("User-Agent", "gator")
+
+	cli := &http.Client{}
+	res, err := cli.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
 	defer res.Body.Close()
 
-	rssFeed := &RSSFeed{}
-	if err := xml.Unmarshal(body, rssFeed); err != nil {
-		return &RSSFeed{}, err
+	if res.StatusCode >= 400 {
+		return nil, nil, fmt.Errorf("could not fetch %v: %v", rawURL, res.Status)
 	}
-	rssFeed.unescapeString()
 
-	return rssFeed, nil
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return body, res.Request.URL, nil
 }
 
 func (rssFeed *RSSFeed) unescapeString() {
@@ -59,5 +230,10 @@ func (rssFeed *RSSFeed) unescapeString() {
 	for i := range rssFeed.Channel.Item {
 		rssFeed.Channel.Item[i].Title = html.UnescapeString(rssFeed.Channel.Item[i].Title)
 		rssFeed.Channel.Item[i].Description = html.UnescapeString(rssFeed.Channel.Item[i].Description)
+		rssFeed.Channel.Item[i].Author = html.UnescapeString(strings.TrimSpace(rssFeed.Channel.Item[i].Author))
+		rssFeed.Channel.Item[i].Creator = html.UnescapeString(strings.TrimSpace(rssFeed.Channel.Item[i].Creator))
+		for j := range rssFeed.Channel.Item[i].Categories {
+			rssFeed.Channel.Item[i].Categories[j] = html.UnescapeString(strings.TrimSpace(rssFeed.Channel.Item[i].Categories[j]))
+		}
 	}
 }
@@ -1,6 +1,8 @@
 package state
 
 import (
+	"database/sql"
+
 	"github.com/acehotel33/bootdev-gator/internal/config"
 	"github.com/acehotel33/bootdev-gator/internal/database"
 )
@@ -8,6 +10,8 @@ import (
 type State struct {
 	Cfg *config.Config
 	DB  *database.Queries
+	// Conn is the connection behind DB, for starting transactions
+	Conn *sql.DB
 }
 
 func InitializeState(cfg *config.Config) (*State, error) {
@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/acehotel33/bootdev-gator/internal/htmltext"
+	"golang.org/x/term"
+)
+
+const helpLine = "j/k move  h/l/tab pane  enter open  r read  s star  R refresh  q quit"
+
+func (r *Reader) draw() {
+	w, h, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || w < 40 || h < 8 {
+		w, h = 80, 24
+	}
+	r.width, r.height = w, h
+
+	feedsW := max(w/5, 16)
+	postsW := max(w*2/5, 24)
+	articleW := w - feedsW - postsW - 2
+	rows := r.paneHeight()
+
+	feeds := r.feedLines(feedsW, rows)
+	posts := r.postLines(postsW, rows)
+	article := r.articleLines(articleW, rows)
+
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	b.WriteString("\x1b[1m" + fit(" gator - "+r.user.Name, w) + "\x1b[0m\r\n")
+	b.WriteString(r.title("Feeds", paneFeeds, feedsW) + "│" + r.title("Posts", panePosts, postsW) + "│" + r.title("Article", paneArticle, articleW) + "\r\n")
+	for i := 0; i < rows; i++ {
+		b.WriteString(feeds[i] + "│" + posts[i] + "│" + article[i] + "\r\n")
+	}
+
+	status := r.status
+	if status == "" {
+		status = helpLine
+	}
+	b.WriteString("\x1b[7m" + fit(" "+status, w) + "\x1b[0m")
+
+	fmt.Print(b.String())
+}
+
+// paneHeight is the number of content rows available to each pane, leaving
+// room for the header, pane titles and status line
+func (r *Reader) paneHeight() int {
+	return max(r.height-3, 1)
+}
+
+func (r *Reader) title(name string, p pane, width int) string {
+	if r.focus == p {
+		return "\x1b[1;4m" + fit(" "+name, width) + "\x1b[0m"
+	}
+	return fit(" "+name, width)
+}
+
+func (r *Reader) feedLines(width, rows int) []string {
+	items := []string{"All feeds"}
+	for _, feed := range r.feeds {
+		items = append(items, feed.FeedName)
+	}
+	return r.list(items, r.feedIdx, paneFeeds, width, rows)
+}
+
+func (r *Reader) postLines(width, rows int) []string {
+	items := make([]string, len(r.posts))
+	for i, post := range r.posts {
+		marker := []rune("  ")
+		if !post.Read {
+			marker[0] = '●'
+		}
+		if post.Starred {
+			marker[1] = '★'
+		}
+		items[i] = string(marker) + " " + nullString(post.Title)
+	}
+	return r.list(items, r.postIdx, panePosts, width, rows)
+}
+
+func (r *Reader) list(items []string, selected int, p pane, width, rows int) []string {
+	offset := 0
+	if selected >= rows {
+		offset = selected - rows + 1
+	}
+
+	lines := make([]string, rows)
+	for i := range lines {
+		idx := offset + i
+		if idx >= len(items) {
+			lines[i] = fit("", width)
+			continue
+		}
+
+		line := fit(" "+items[idx], width)
+		if idx == selected {
+			if r.focus == p {
+				line = "\x1b[7m" + line + "\x1b[0m"
+			} else {
+				line = "\x1b[1m" + line + "\x1b[0m"
+			}
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+func (r *Reader) articleLines(width, rows int) []string {
+	var content []string
+	if post, ok := r.selectedPost(); ok {
+		content = append(content, wrapText(nullString(post.Title), width-2)...)
+		meta := post.FeedName
+		if post.Author.Valid {
+			meta += " - " + post.Author.String
+		}
+		if post.PublishedAt.Valid {
+			meta += " - " + post.PublishedAt.Time.Format("2006-01-02 15:04")
+		}
+		content = append(content, meta, post.Url, "")
+		body := nullString(post.Description)
+		if post.Content.Valid {
+			body = post.Content.String
+		}
+		description := htmltext.Render(body, htmltext.Options{Width: width - 2})
+		content = append(content, strings.Split(description, "\n")...)
+	}
+
+	r.articleScroll = clamp(r.articleScroll, 0, max(len(content)-rows, 0))
+
+	lines := make([]string, rows)
+	for i := range lines {
+		idx := r.articleScroll + i
+		if idx >= len(content) {
+			lines[i] = fit("", width)
+			continue
+		}
+		lines[i] = fit(" "+content[idx], width)
+	}
+	return lines
+}
+
+// fit truncates or pads s to exactly width runes, replacing control
+// characters so they cannot break the layout
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	runes := make([]rune, 0, width)
+	for _, c := range s {
+		if len(runes) == width {
+			break
+		}
+		if unicode.IsControl(c) {
+			c = ' '
+		}
+		runes = append(runes, c)
+	}
+	if len(runes) == width && len([]rune(s)) > width {
+		runes[width-1] = '…'
+	}
+	return string(runes) + strings.Repeat(" ", width-len(runes))
+}
+
+func wrapText(s string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := ""
+		for _, word := range words {
+			switch {
+			case line == "":
+				line = word
+			case len([]rune(line))+1+len([]rune(word)) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"io"
+)
+
+type key int
+
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyTab
+	keyEnter
+	keyToggleRead
+	keyToggleStar
+	keyRefresh
+	keyQuit
+)
+
+// readKeys reads raw terminal input and sends the decoded keys on keys
+// until the input is closed
+func readKeys(in io.Reader, keys chan<- key) {
+	defer close(keys)
+
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, k := range decodeKeys(buf[:n]) {
+			keys <- k
+		}
+	}
+}
+
+func decodeKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		if b[0] == 0x1b && len(b) >= 3 && b[1] == '[' {
+			switch b[2] {
+			case 'A':
+				keys = append(keys, keyUp)
+			case 'B':
+				keys = append(keys, keyDown)
+			case 'C':
+				keys = append(keys, keyRight)
+			case 'D':
+				keys = append(keys, keyLeft)
+			case '5', '6':
+				if len(b) >= 4 && b[3] == '~' {
+					if b[2] == '5' {
+						keys = append(keys, keyPageUp)
+					} else {
+						keys = append(keys, keyPageDown)
+					}
+					b = b[4:]
+					continue
+				}
+			}
+			b = b[3:]
+			continue
+		}
+
+		switch b[0] {
+		case 'k':
+			keys = append(keys, keyUp)
+		case 'j':
+			keys = append(keys, keyDown)
+		case 'h':
+			keys = append(keys, keyLeft)
+		case 'l':
+			keys = append(keys, keyRight)
+		case 'b':
+			keys = append(keys, keyPageUp)
+		case ' ':
+			keys = append(keys, keyPageDown)
+		case '\t':
+			keys = append(keys, keyTab)
+		case '\r', '\n':
+			keys = append(keys, keyEnter)
+		case 'r':
+			keys = append(keys, keyToggleRead)
+		case 's':
+			keys = append(keys, keyToggleStar)
+		case 'R':
+			keys = append(keys, keyRefresh)
+		case 'q', 0x03:
+			keys = append(keys, keyQuit)
+		}
+		b = b[1:]
+	}
+	return keys
+}
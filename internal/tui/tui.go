@@ -0,0 +1,290 @@
+package tui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/google/uuid"
+	"golang.org/x/term"
+)
+
+const postLimit = 200
+
+type pane int
+
+const (
+	paneFeeds pane = iota
+	panePosts
+	paneArticle
+)
+
+type Reader struct {
+	db      *database.Queries
+	user    database.User
+	refresh time.Duration
+
+	feeds []database.GetFeedFollowsForUserRow
+	posts []database.GetPostsWithStateForUserRow
+
+	focus         pane
+	feedIdx       int
+	postIdx       int
+	articleScroll int
+
+	width  int
+	height int
+	status string
+}
+
+func New(db *database.Queries, user database.User, refresh time.Duration) *Reader {
+	return &Reader{
+		db:      db,
+		user:    user,
+		refresh: refresh,
+	}
+}
+
+func (r *Reader) Run(ctx context.Context) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("reader needs an interactive terminal")
+	}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, oldState)
+
+	// Switch to the alternate screen and hide the cursor while running
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	if err := r.loadFeeds(ctx); err != nil {
+		return err
+	}
+	if err := r.loadPosts(ctx); err != nil {
+		return err
+	}
+
+	keys := make(chan key)
+	go readKeys(os.Stdin, keys)
+
+	ticker := time.NewTicker(r.refresh)
+	defer ticker.Stop()
+
+	for {
+		r.draw()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.reload(ctx); err != nil {
+				r.status = err.Error()
+			} else {
+				r.status = "refreshed at " + time.Now().Format("15:04:05")
+			}
+		case k, ok := <-keys:
+			if !ok || k == keyQuit {
+				return nil
+			}
+			if err := r.handleKey(ctx, k); err != nil {
+				r.status = err.Error()
+			}
+		}
+	}
+}
+
+func (r *Reader) handleKey(ctx context.Context, k key) error {
+	switch k {
+	case keyUp:
+		r.move(-1)
+	case keyDown:
+		r.move(1)
+	case keyPageUp:
+		r.move(-r.paneHeight())
+	case keyPageDown:
+		r.move(r.paneHeight())
+	case keyLeft:
+		if r.focus > paneFeeds {
+			r.focus--
+		}
+	case keyRight, keyTab:
+		if r.focus < paneArticle {
+			r.focus++
+		}
+		if r.focus == paneArticle {
+			return r.markRead(ctx, true)
+		}
+	case keyEnter:
+		switch r.focus {
+		case paneFeeds:
+			r.focus = panePosts
+		case panePosts:
+			r.focus = paneArticle
+			return r.markRead(ctx, true)
+		}
+	case keyToggleRead:
+		if post, ok := r.selectedPost(); ok {
+			return r.markRead(ctx, !post.Read)
+		}
+	case keyToggleStar:
+		return r.toggleStar(ctx)
+	case keyRefresh:
+		if err := r.reload(ctx); err != nil {
+			return err
+		}
+		r.status = "refreshed at " + time.Now().Format("15:04:05")
+	}
+	return nil
+}
+
+func (r *Reader) move(delta int) {
+	switch r.focus {
+	case paneFeeds:
+		// Index 0 is the "All feeds" entry
+		idx := clamp(r.feedIdx+delta, 0, len(r.feeds))
+		if idx != r.feedIdx {
+			r.feedIdx = idx
+			r.postIdx = 0
+			r.articleScroll = 0
+			if err := r.loadPosts(context.Background()); err != nil {
+				r.status = err.Error()
+			}
+		}
+	case panePosts:
+		idx := clamp(r.postIdx+delta, 0, len(r.posts)-1)
+		if idx != r.postIdx {
+			r.postIdx = idx
+			r.articleScroll = 0
+		}
+	case paneArticle:
+		r.articleScroll = max(r.articleScroll+delta, 0)
+	}
+}
+
+func (r *Reader) loadFeeds(ctx context.Context) error {
+	feeds, err := r.db.GetFeedFollowsForUser(ctx, uuid.NullUUID{UUID: r.user.ID, Valid: true})
+	if err != nil {
+		return err
+	}
+	r.feeds = feeds
+	r.feedIdx = clamp(r.feedIdx, 0, len(r.feeds))
+	return nil
+}
+
+func (r *Reader) loadPosts(ctx context.Context) error {
+	params := database.GetPostsWithStateForUserParams{
+		UserID:   uuid.NullUUID{UUID: r.user.ID, Valid: true},
+		RowLimit: postLimit,
+	}
+	if r.feedIdx > 0 {
+		params.FeedID = r.feeds[r.feedIdx-1].FeedID
+	}
+
+	posts, err := r.db.GetPostsWithStateForUser(ctx, params)
+	if err != nil {
+		return err
+	}
+	r.posts = posts
+	r.postIdx = clamp(r.postIdx, 0, len(r.posts)-1)
+	return nil
+}
+
+// reload refreshes feeds and posts from the database while keeping the
+// current selections on the same rows where possible
+func (r *Reader) reload(ctx context.Context) error {
+	var feedID uuid.NullUUID
+	if r.feedIdx > 0 {
+		feedID = r.feeds[r.feedIdx-1].FeedID
+	}
+	var postID uuid.UUID
+	if post, ok := r.selectedPost(); ok {
+		postID = post.ID
+	}
+
+	if err := r.loadFeeds(ctx); err != nil {
+		return err
+	}
+	r.feedIdx = 0
+	for i, feed := range r.feeds {
+		if feedID.Valid && feed.FeedID == feedID {
+			r.feedIdx = i + 1
+		}
+	}
+
+	if err := r.loadPosts(ctx); err != nil {
+		return err
+	}
+	for i, post := range r.posts {
+		if post.ID == postID {
+			r.postIdx = i
+		}
+	}
+	return nil
+}
+
+func (r *Reader) selectedPost() (database.GetPostsWithStateForUserRow, bool) {
+	if r.postIdx < 0 || r.postIdx >= len(r.posts) {
+		return database.GetPostsWithStateForUserRow{}, false
+	}
+	return r.posts[r.postIdx], true
+}
+
+func (r *Reader) markRead(ctx context.Context, read bool) error {
+	post, ok := r.selectedPost()
+	if !ok || post.Read == read {
+		return nil
+	}
+	err := r.db.SetPostRead(ctx, database.SetPostReadParams{
+		UserID:    r.user.ID,
+		PostID:    post.ID,
+		CreatedAt: time.Now(),
+		Read:      read,
+	})
+	if err != nil {
+		return err
+	}
+	r.posts[r.postIdx].Read = read
+	return nil
+}
+
+func (r *Reader) toggleStar(ctx context.Context) error {
+	post, ok := r.selectedPost()
+	if !ok {
+		return nil
+	}
+	err := r.db.SetPostStarred(ctx, database.SetPostStarredParams{
+		UserID:    r.user.ID,
+		PostID:    post.ID,
+		CreatedAt: time.Now(),
+		Starred:   !post.Starred,
+	})
+	if err != nil {
+		return err
+	}
+	r.posts[r.postIdx].Starred = !post.Starred
+	return nil
+}
+
+func nullString(s sql.NullString) string {
+	if !s.Valid {
+		return ""
+	}
+	return s.String
+}
+
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}
@@ -0,0 +1,321 @@
+package web
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/auth"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/acehotel33/bootdev-gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+const postsPerPage = 50
+
+type page struct {
+	User  *database.User
+	Error string
+}
+
+type postView struct {
+	ID          uuid.UUID
+	Title       string
+	URL         string
+	FeedName    string
+	Author      string
+	PublishedAt time.Time
+	Read        bool
+	Starred     bool
+	Body        string
+	CommentsURL string
+}
+
+type postsPage struct {
+	page
+	Heading string
+	Posts   []postView
+}
+
+type postPage struct {
+	page
+	Post postView
+}
+
+type folderView struct {
+	Name  string
+	Feeds []database.GetFeedFollowsForUserRow
+}
+
+type followingPage struct {
+	page
+	Folders []folderView
+}
+
+func (srv *Server) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	srv.renderLogin(w, r, http.StatusOK, "")
+}
+
+func (srv *Server) renderLogin(w http.ResponseWriter, r *http.Request, status int, message string) {
+	srv.render(w, status, "login", page{Error: message})
+}
+
+func (srv *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	user, err := srv.db.GetUser(r.Context(), r.FormValue("name"))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err == nil && !user.PasswordHash.Valid {
+		srv.renderLogin(w, r, http.StatusUnauthorized, "no password set, ask an admin to run gator user passwd")
+		return
+	}
+	if !user.PasswordHash.Valid || auth.CheckPassword(user.PasswordHash.String, r.FormValue("password")) != nil {
+		srv.renderLogin(w, r, http.StatusUnauthorized, auth.ErrWrongPassword.Error())
+		return
+	}
+
+	if err := srv.startSession(w, r, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (srv *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if err := srv.endSession(w, r); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+func (srv *Server) handleUnread(w http.ResponseWriter, r *http.Request) {
+	user, _ := UserFrom(r)
+	rows, err := srv.db.GetUnreadPostsForUser(r.Context(), database.GetUnreadPostsForUserParams{
+		UserID:   uuid.NullUUID{UUID: user.ID, Valid: true},
+		RowLimit: postsPerPage,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	posts := make([]postView, 0, len(rows))
+	for _, row := range rows {
+		posts = append(posts, postView{
+			ID:          row.ID,
+			Title:       row.Title.String,
+			URL:         row.Url,
+			FeedName:    row.FeedName,
+			Author:      row.Author.String,
+			PublishedAt: publishedAt(row.PublishedAt, row.CreatedAt),
+			Read:        row.Read,
+			Starred:     row.Starred,
+		})
+	}
+	srv.render(w, http.StatusOK, "posts", postsPage{page: page{User: &user}, Heading: "Unread", Posts: posts})
+}
+
+func (srv *Server) handleAllPosts(w http.ResponseWriter, r *http.Request) {
+	user, _ := UserFrom(r)
+	rows, err := srv.db.GetPostsWithStateForUser(r.Context(), database.GetPostsWithStateForUserParams{
+		UserID:   uuid.NullUUID{UUID: user.ID, Valid: true},
+		RowLimit: postsPerPage,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	posts := make([]postView, 0, len(rows))
+	for _, row := range rows {
+		posts = append(posts, postView{
+			ID:          row.ID,
+			Title:       row.Title.String,
+			URL:         row.Url,
+			FeedName:    row.FeedName,
+			Author:      row.Author.String,
+			PublishedAt: publishedAt(row.PublishedAt, row.CreatedAt),
+			Read:        row.Read,
+			Starred:     row.Starred,
+		})
+	}
+	srv.render(w, http.StatusOK, "posts", postsPage{page: page{User: &user}, Heading: "All posts", Posts: posts})
+}
+
+// handlePost shows one post as text and marks it read
+func (srv *Server) handlePost(w http.ResponseWriter, r *http.Request) {
+	user, _ := UserFrom(r)
+	row, ok := srv.loadPost(w, r, user)
+	if !ok {
+		return
+	}
+
+	if !row.Read {
+		err := srv.db.SetPostRead(r.Context(), database.SetPostReadParams{
+			UserID:    user.ID,
+			PostID:    row.ID,
+			CreatedAt: time.Now(),
+			Read:      true,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		row.Read = true
+	}
+
+	body := row.Description.String
+	if row.Content.Valid {
+		body = row.Content.String
+	}
+	post := postView{
+		ID:          row.ID,
+		Title:       row.Title.String,
+		URL:         row.Url,
+		FeedName:    row.FeedName,
+		Author:      row.Author.String,
+		PublishedAt: publishedAt(row.PublishedAt, row.CreatedAt),
+		Read:        row.Read,
+		Starred:     row.Starred,
+		Body:        htmltext.Render(body, htmltext.Options{}),
+		CommentsURL: row.CommentsUrl.String,
+	}
+	srv.render(w, http.StatusOK, "post", postPage{page: page{User: &user}, Post: post})
+}
+
+func (srv *Server) handleSetRead(w http.ResponseWriter, r *http.Request) {
+	user, _ := UserFrom(r)
+	row, ok := srv.loadPost(w, r, user)
+	if !ok {
+		return
+	}
+
+	err := srv.db.SetPostRead(r.Context(), database.SetPostReadParams{
+		UserID:    user.ID,
+		PostID:    row.ID,
+		CreatedAt: time.Now(),
+		Read:      r.FormValue("read") == "true",
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r, "/posts/"+row.ID.String())
+}
+
+func (srv *Server) handleSetStarred(w http.ResponseWriter, r *http.Request) {
+	user, _ := UserFrom(r)
+	row, ok := srv.loadPost(w, r, user)
+	if !ok {
+		return
+	}
+
+	err := srv.db.SetPostStarred(r.Context(), database.SetPostStarredParams{
+		UserID:    user.ID,
+		PostID:    row.ID,
+		CreatedAt: time.Now(),
+		Starred:   r.FormValue("starred") == "true",
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r, "/posts/"+row.ID.String())
+}
+
+// loadPost finds the post named in the path among the user's followed
+// feeds, writing an error response when it can't
+func (srv *Server) loadPost(w http.ResponseWriter, r *http.Request, user database.User) (database.GetPostWithStateForUserRow, bool) {
+	postID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return database.GetPostWithStateForUserRow{}, false
+	}
+
+	row, err := srv.db.GetPostWithStateForUser(r.Context(), database.GetPostWithStateForUserParams{
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		PostID: postID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return row, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return row, false
+	}
+	return row, true
+}
+
+func (srv *Server) handleFollowing(w http.ResponseWriter, r *http.Request) {
+	srv.renderFollowing(w, r, http.StatusOK, "")
+}
+
+func (srv *Server) renderFollowing(w http.ResponseWriter, r *http.Request, status int, message string) {
+	user, _ := UserFrom(r)
+	follows, err := srv.db.GetFeedFollowsForUser(r.Context(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Unfiled feeds first, then one group per folder in name order
+	var folders []folderView
+	byFolder := map[string][]database.GetFeedFollowsForUserRow{}
+	var names []string
+	for _, follow := range follows {
+		name := follow.Folder.String
+		if _, ok := byFolder[name]; !ok && name != "" {
+			names = append(names, name)
+		}
+		byFolder[name] = append(byFolder[name], follow)
+	}
+	sort.Strings(names)
+	if unfiled := byFolder[""]; len(unfiled) > 0 {
+		folders = append(folders, folderView{Feeds: unfiled})
+	}
+	for _, name := range names {
+		folders = append(folders, folderView{Name: name, Feeds: byFolder[name]})
+	}
+
+	srv.render(w, status, "following", followingPage{page: page{User: &user, Error: message}, Folders: folders})
+}
+
+func (srv *Server) handleAddFeed(w http.ResponseWriter, r *http.Request) {
+	user, _ := UserFrom(r)
+	feedURL := strings.TrimSpace(r.FormValue("url"))
+	parsed, err := url.Parse(feedURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		srv.renderFollowing(w, r, http.StatusBadRequest, "enter an http or https URL")
+		return
+	}
+
+	if _, err := srv.addFeed(r.Context(), user, strings.TrimSpace(r.FormValue("name")), feedURL); err != nil {
+		srv.renderFollowing(w, r, http.StatusBadRequest, "could not add feed: "+err.Error())
+		return
+	}
+	http.Redirect(w, r, "/following", http.StatusSeeOther)
+}
+
+func publishedAt(published sql.NullTime, created time.Time) time.Time {
+	if published.Valid {
+		return published.Time
+	}
+	return created
+}
+
+// redirectBack returns to the page a form was posted from when it is on
+// this server, otherwise to fallback
+func redirectBack(w http.ResponseWriter, r *http.Request, fallback string) {
+	target := fallback
+	if referer, err := url.Parse(r.Referer()); err == nil && referer.Host == r.Host && referer.Path != "" {
+		target = referer.RequestURI()
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
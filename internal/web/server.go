@@ -0,0 +1,223 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/auth"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/google/uuid"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+const sessionCookie = "gator_session"
+
+var errNoSession = errors.New("no session")
+
+// Store is the part of the database the web UI uses. *database.Queries
+// implements it.
+type Store interface {
+	GetUser(ctx context.Context, name string) (database.User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error)
+	GetUnreadPostsForUser(ctx context.Context, arg database.GetUnreadPostsForUserParams) ([]database.GetUnreadPostsForUserRow, error)
+	GetPostsWithStateForUser(ctx context.Context, arg database.GetPostsWithStateForUserParams) ([]database.GetPostsWithStateForUserRow, error)
+	GetPostWithStateForUser(ctx context.Context, arg database.GetPostWithStateForUserParams) (database.GetPostWithStateForUserRow, error)
+	SetPostRead(ctx context.Context, arg database.SetPostReadParams) error
+	SetPostStarred(ctx context.Context, arg database.SetPostStarredParams) error
+	GetFeedFollowsForUser(ctx context.Context, userID uuid.NullUUID) ([]database.GetFeedFollowsForUserRow, error)
+	CreateSession(ctx context.Context, arg database.CreateSessionParams) (database.Session, error)
+	GetSessionByHash(ctx context.Context, arg database.GetSessionByHashParams) (database.Session, error)
+	DeleteSessionByHash(ctx context.Context, tokenHash string) error
+}
+
+// AddFeedFunc adds or follows the feed at url for user, the same way the
+// addfeed and follow commands do
+type AddFeedFunc func(ctx context.Context, user database.User, name, url string) (database.Feed, error)
+
+// Server is the web UI. It is an http.Handler, so it can be run with
+// http.ListenAndServe or exercised with httptest.
+type Server struct {
+	db      Store
+	addFeed AddFeedFunc
+	mux     *http.ServeMux
+	pages   map[string]*template.Template
+}
+
+type contextKey int
+
+const userKey contextKey = iota
+
+func New(db Store, addFeed AddFeedFunc) (*Server, error) {
+	srv := &Server{
+		db:      db,
+		addFeed: addFeed,
+		mux:     http.NewServeMux(),
+		pages:   map[string]*template.Template{},
+	}
+
+	for _, page := range []string{"login", "posts", "post", "following"} {
+		tmpl, err := template.New("layout.html").Funcs(templateFuncs).ParseFS(templateFS, "templates/layout.html", "templates/"+page+".html")
+		if err != nil {
+			return nil, err
+		}
+		srv.pages[page] = tmpl
+	}
+
+	srv.mux.HandleFunc("GET /login", srv.handleLoginPage)
+	srv.mux.HandleFunc("POST /login", srv.handleLogin)
+	srv.mux.HandleFunc("POST /logout", srv.handleLogout)
+
+	srv.Handle("GET /{$}", http.RedirectHandler("/unread", http.StatusSeeOther))
+	srv.Handle("GET /unread", http.HandlerFunc(srv.handleUnread))
+	srv.Handle("GET /posts", http.HandlerFunc(srv.handleAllPosts))
+	srv.Handle("GET /posts/{id}", http.HandlerFunc(srv.handlePost))
+	srv.Handle("POST /posts/{id}/read", http.HandlerFunc(srv.handleSetRead))
+	srv.Handle("POST /posts/{id}/star", http.HandlerFunc(srv.handleSetStarred))
+	srv.Handle("GET /following", http.HandlerFunc(srv.handleFollowing))
+	srv.Handle("POST /following", http.HandlerFunc(srv.handleAddFeed))
+
+	return srv, nil
+}
+
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv.mux.ServeHTTP(w, r)
+}
+
+// Handle registers h for pattern behind login, so other parts of gator can
+// add pages that use UserFrom
+func (srv *Server) Handle(pattern string, h http.Handler) {
+	srv.mux.Handle(pattern, srv.requireUser(h))
+}
+
+// UserFrom returns the logged in user of a request served through Handle
+func UserFrom(r *http.Request) (database.User, bool) {
+	user, ok := r.Context().Value(userKey).(database.User)
+	return user, ok
+}
+
+func (srv *Server) requireUser(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := srv.sessionUser(r)
+		if errors.Is(err, errNoSession) {
+			if r.Method == http.MethodGet {
+				http.Redirect(w, r, "/login", http.StatusSeeOther)
+				return
+			}
+			http.Error(w, "not logged in", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		user, err := srv.db.GetUserByID(r.Context(), userID)
+		if err != nil {
+			srv.endSession(w, r)
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey, user)))
+	})
+}
+
+// startSession stores a new session for userID in the sessions table, like
+// the login command does, and hands its token to the browser
+func (srv *Server) startSession(w http.ResponseWriter, r *http.Request, userID uuid.UUID) error {
+	token, hash, err := auth.NewSessionToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = srv.db.CreateSession(r.Context(), database.CreateSessionParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		ExpiresAt: now.Add(auth.SessionLifetime),
+		UserID:    userID,
+		TokenHash: hash,
+	})
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(auth.SessionLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// sessionUser returns the user of the request's session. Expired sessions
+// and ones revoked by a password change are not found.
+func (srv *Server) sessionUser(r *http.Request) (uuid.UUID, error) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil || cookie.Value == "" {
+		return uuid.UUID{}, errNoSession
+	}
+
+	session, err := srv.db.GetSessionByHash(r.Context(), database.GetSessionByHashParams{
+		TokenHash: auth.HashToken(cookie.Value),
+		ExpiresAt: time.Now(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.UUID{}, errNoSession
+	}
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return session.UserID, nil
+}
+
+func (srv *Server) endSession(w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil || cookie.Value == "" {
+		return nil
+	}
+	return srv.db.DeleteSessionByHash(r.Context(), auth.HashToken(cookie.Value))
+}
+
+var templateFuncs = template.FuncMap{
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("Jan 2, 2006 15:04")
+	},
+}
+
+func (srv *Server) render(w http.ResponseWriter, status int, page string, data any) {
+	var b bytes.Buffer
+	if err := srv.pages[page].Execute(&b, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(b.Bytes())
+}
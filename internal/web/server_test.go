@@ -0,0 +1,440 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acehotel33/bootdev-gator/internal/auth"
+	"github.com/acehotel33/bootdev-gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// fakeStore keeps users, posts and follows in memory and scopes posts to
+// followed feeds the way the SQL queries do
+type fakeStore struct {
+	users    []database.User
+	posts    []fakePost
+	follows  map[uuid.UUID]map[uuid.UUID]bool
+	read     map[[2]uuid.UUID]bool
+	starred  map[[2]uuid.UUID]bool
+	sessions map[string]database.Session
+}
+
+type fakePost struct {
+	id     uuid.UUID
+	feedID uuid.UUID
+	title  string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		follows:  map[uuid.UUID]map[uuid.UUID]bool{},
+		read:     map[[2]uuid.UUID]bool{},
+		starred:  map[[2]uuid.UUID]bool{},
+		sessions: map[string]database.Session{},
+	}
+}
+
+func (f *fakeStore) addUser(t *testing.T, name, password string) database.User {
+	t.Helper()
+	user := database.User{ID: uuid.New(), Name: name}
+	if password != "" {
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		user.PasswordHash = sql.NullString{String: hash, Valid: true}
+	}
+	f.users = append(f.users, user)
+	return user
+}
+
+func (f *fakeStore) addPost(feedID uuid.UUID, title string) uuid.UUID {
+	id := uuid.New()
+	f.posts = append(f.posts, fakePost{id: id, feedID: feedID, title: title})
+	return id
+}
+
+func (f *fakeStore) follow(userID, feedID uuid.UUID) {
+	if f.follows[userID] == nil {
+		f.follows[userID] = map[uuid.UUID]bool{}
+	}
+	f.follows[userID][feedID] = true
+}
+
+func (f *fakeStore) visible(userID uuid.UUID) []fakePost {
+	var posts []fakePost
+	for _, post := range f.posts {
+		if f.follows[userID][post.feedID] {
+			posts = append(posts, post)
+		}
+	}
+	return posts
+}
+
+func (f *fakeStore) GetUser(ctx context.Context, name string) (database.User, error) {
+	for _, user := range f.users {
+		if user.Name == name {
+			return user, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (f *fakeStore) GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error) {
+	for _, user := range f.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (f *fakeStore) GetUnreadPostsForUser(ctx context.Context, arg database.GetUnreadPostsForUserParams) ([]database.GetUnreadPostsForUserRow, error) {
+	var rows []database.GetUnreadPostsForUserRow
+	for _, post := range f.visible(arg.UserID.UUID) {
+		key := [2]uuid.UUID{arg.UserID.UUID, post.id}
+		if f.read[key] {
+			continue
+		}
+		rows = append(rows, database.GetUnreadPostsForUserRow{
+			ID:        post.id,
+			CreatedAt: time.Now(),
+			Title:     sql.NullString{String: post.title, Valid: true},
+			FeedID:    uuid.NullUUID{UUID: post.feedID, Valid: true},
+			Starred:   f.starred[key],
+		})
+	}
+	return rows, nil
+}
+
+func (f *fakeStore) GetPostsWithStateForUser(ctx context.Context, arg database.GetPostsWithStateForUserParams) ([]database.GetPostsWithStateForUserRow, error) {
+	var rows []database.GetPostsWithStateForUserRow
+	for _, post := range f.visible(arg.UserID.UUID) {
+		key := [2]uuid.UUID{arg.UserID.UUID, post.id}
+		rows = append(rows, database.GetPostsWithStateForUserRow{
+			ID:        post.id,
+			CreatedAt: time.Now(),
+			Title:     sql.NullString{String: post.title, Valid: true},
+			FeedID:    uuid.NullUUID{UUID: post.feedID, Valid: true},
+			Read:      f.read[key],
+			Starred:   f.starred[key],
+		})
+	}
+	return rows, nil
+}
+
+func (f *fakeStore) GetPostWithStateForUser(ctx context.Context, arg database.GetPostWithStateForUserParams) (database.GetPostWithStateForUserRow, error) {
+	for _, post := range f.visible(arg.UserID.UUID) {
+		if post.id != arg.PostID {
+			continue
+		}
+		key := [2]uuid.UUID{arg.UserID.UUID, post.id}
+		return database.GetPostWithStateForUserRow{
+			ID:        post.id,
+			CreatedAt: time.Now(),
+			Title:     sql.NullString{String: post.title, Valid: true},
+			FeedID:    uuid.NullUUID{UUID: post.feedID, Valid: true},
+			Read:      f.read[key],
+			Starred:   f.starred[key],
+		}, nil
+	}
+	return database.GetPostWithStateForUserRow{}, sql.ErrNoRows
+}
+
+func (f *fakeStore) SetPostRead(ctx context.Context, arg database.SetPostReadParams) error {
+	f.read[[2]uuid.UUID{arg.UserID, arg.PostID}] = arg.Read
+	return nil
+}
+
+func (f *fakeStore) SetPostStarred(ctx context.Context, arg database.SetPostStarredParams) error {
+	f.starred[[2]uuid.UUID{arg.UserID, arg.PostID}] = arg.Starred
+	return nil
+}
+
+func (f *fakeStore) GetFeedFollowsForUser(ctx context.Context, userID uuid.NullUUID) ([]database.GetFeedFollowsForUserRow, error) {
+	return nil, nil
+}
+
+func (f *fakeStore) CreateSession(ctx context.Context, arg database.CreateSessionParams) (database.Session, error) {
+	session := database.Session(arg)
+	f.sessions[arg.TokenHash] = session
+	return session, nil
+}
+
+func (f *fakeStore) GetSessionByHash(ctx context.Context, arg database.GetSessionByHashParams) (database.Session, error) {
+	session, ok := f.sessions[arg.TokenHash]
+	if !ok || !session.ExpiresAt.After(arg.ExpiresAt) {
+		return database.Session{}, sql.ErrNoRows
+	}
+	return session, nil
+}
+
+func (f *fakeStore) DeleteSessionByHash(ctx context.Context, tokenHash string) error {
+	delete(f.sessions, tokenHash)
+	return nil
+}
+
+// deleteSessionsForUser stands in for DeleteSessionsForUser, which passwd
+// runs to log a user out everywhere
+func (f *fakeStore) deleteSessionsForUser(userID uuid.UUID) {
+	for hash, session := range f.sessions {
+		if session.UserID == userID {
+			delete(f.sessions, hash)
+		}
+	}
+}
+
+func newTestServer(t *testing.T, store *fakeStore) *Server {
+	t.Helper()
+	srv, err := New(store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+func do(srv *Server, method, target string, form url.Values, cookie *http.Cookie) *httptest.ResponseRecorder {
+	var req *http.Request
+	if form != nil {
+		req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+// login logs in through the form and returns the session cookie
+func login(t *testing.T, srv *Server, name, password string) *http.Cookie {
+	t.Helper()
+	rec := do(srv, "POST", "/login", url.Values{"name": {name}, "password": {password}}, nil)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("login as %v: status %d, want %d", name, rec.Code, http.StatusSeeOther)
+	}
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == sessionCookie && cookie.Value != "" {
+			return cookie
+		}
+	}
+	t.Fatalf("login as %v: no session cookie", name)
+	return nil
+}
+
+func TestRequiresLogin(t *testing.T) {
+	srv := newTestServer(t, newFakeStore())
+
+	rec := do(srv, "GET", "/unread", nil, nil)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login" {
+		t.Errorf("GET /unread: status %d location %q, want redirect to /login", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec = do(srv, "POST", "/posts/"+uuid.NewString()+"/read", url.Values{"read": {"true"}}, nil)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST read without session: status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	rec = do(srv, "GET", "/unread", nil, &http.Cookie{Name: sessionCookie, Value: "made-up"})
+	if rec.Code != http.StatusSeeOther {
+		t.Errorf("GET /unread with unknown session: status %d, want %d", rec.Code, http.StatusSeeOther)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	store := newFakeStore()
+	store.addUser(t, "alice", "correct horse")
+	store.addUser(t, "legacy", "")
+	srv := newTestServer(t, store)
+
+	tests := []struct {
+		name     string
+		user     string
+		password string
+	}{
+		{"wrong password", "alice", "wrong password"},
+		{"unknown user", "nobody", "correct horse"},
+		{"no password set", "legacy", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(srv, "POST", "/login", url.Values{"name": {tt.user}, "password": {tt.password}}, nil)
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("status %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+			if len(rec.Result().Cookies()) != 0 {
+				t.Error("failed login set a cookie")
+			}
+		})
+	}
+
+	cookie := login(t, srv, "alice", "correct horse")
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("session cookie should be HttpOnly and SameSite=Lax: %+v", cookie)
+	}
+	if cookie.MaxAge != int(auth.SessionLifetime.Seconds()) {
+		t.Errorf("session cookie MaxAge = %d, want %d", cookie.MaxAge, int(auth.SessionLifetime.Seconds()))
+	}
+	if cookie.Secure {
+		t.Error("session cookie is Secure on a plain HTTP request")
+	}
+	if rec := do(srv, "GET", "/unread", nil, cookie); rec.Code != http.StatusOK {
+		t.Fatalf("GET /unread after login: status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	do(srv, "POST", "/logout", nil, cookie)
+	if rec := do(srv, "GET", "/unread", nil, cookie); rec.Code != http.StatusSeeOther {
+		t.Errorf("GET /unread after logout: status %d, want %d", rec.Code, http.StatusSeeOther)
+	}
+}
+
+func TestSessionsStoredInDatabase(t *testing.T) {
+	store := newFakeStore()
+	alice := store.addUser(t, "alice", "correct horse")
+	srv := newTestServer(t, store)
+
+	cookie := login(t, srv, "alice", "correct horse")
+	session, ok := store.sessions[auth.HashToken(cookie.Value)]
+	if !ok {
+		t.Fatal("login did not store the session's token hash")
+	}
+	if _, ok := store.sessions[cookie.Value]; ok {
+		t.Error("the raw session token was stored")
+	}
+
+	// A second server on the same database, as after a restart, knows the
+	// session
+	if rec := do(newTestServer(t, store), "GET", "/unread", nil, cookie); rec.Code != http.StatusOK {
+		t.Errorf("GET /unread on a new server: status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	session.ExpiresAt = time.Now().Add(-time.Minute)
+	store.sessions[session.TokenHash] = session
+	if rec := do(srv, "GET", "/unread", nil, cookie); rec.Code != http.StatusSeeOther {
+		t.Errorf("GET /unread with an expired session: status %d, want %d", rec.Code, http.StatusSeeOther)
+	}
+
+	cookie = login(t, srv, "alice", "correct horse")
+	store.deleteSessionsForUser(alice.ID)
+	if rec := do(srv, "GET", "/unread", nil, cookie); rec.Code != http.StatusSeeOther {
+		t.Errorf("GET /unread after the password changed: status %d, want %d", rec.Code, http.StatusSeeOther)
+	}
+}
+
+func TestSecureCookieOverTLS(t *testing.T) {
+	store := newFakeStore()
+	store.addUser(t, "alice", "correct horse")
+	srv := newTestServer(t, store)
+
+	req := httptest.NewRequest("POST", "https://gator.example/login", strings.NewReader(url.Values{"name": {"alice"}, "password": {"correct horse"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].Secure {
+		t.Errorf("cookies %+v, want one Secure session cookie", cookies)
+	}
+}
+
+func TestPostsScopedToFollows(t *testing.T) {
+	store := newFakeStore()
+	alice := store.addUser(t, "alice", "correct horse")
+	bob := store.addUser(t, "bob", "battery staple")
+	aliceFeed, bobFeed := uuid.New(), uuid.New()
+	store.follow(alice.ID, aliceFeed)
+	store.follow(bob.ID, bobFeed)
+	alicePost := store.addPost(aliceFeed, "Alice's post")
+	bobPost := store.addPost(bobFeed, "Bob's post")
+	srv := newTestServer(t, store)
+
+	cookie := login(t, srv, "alice", "correct horse")
+	for _, page := range []string{"/unread", "/posts"} {
+		rec := do(srv, "GET", page, nil, cookie)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %v: status %d", page, rec.Code)
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, "Alice&#39;s post") {
+			t.Errorf("GET %v doesn't list the followed feed's post", page)
+		}
+		if strings.Contains(body, "Bob&#39;s post") {
+			t.Errorf("GET %v lists a post from a feed alice doesn't follow", page)
+		}
+	}
+
+	if rec := do(srv, "GET", "/posts/"+alicePost.String(), nil, cookie); rec.Code != http.StatusOK {
+		t.Errorf("GET own post: status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !store.read[[2]uuid.UUID{alice.ID, alicePost}] {
+		t.Error("viewing a post didn't mark it read")
+	}
+	if rec := do(srv, "GET", "/posts/"+bobPost.String(), nil, cookie); rec.Code != http.StatusNotFound {
+		t.Errorf("GET unfollowed post: status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := do(srv, "GET", "/posts/not-a-uuid", nil, cookie); rec.Code != http.StatusNotFound {
+		t.Errorf("GET invalid post id: status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestSetReadAndStarred(t *testing.T) {
+	store := newFakeStore()
+	alice := store.addUser(t, "alice", "correct horse")
+	bob := store.addUser(t, "bob", "battery staple")
+	aliceFeed, bobFeed := uuid.New(), uuid.New()
+	store.follow(alice.ID, aliceFeed)
+	store.follow(bob.ID, bobFeed)
+	post := store.addPost(aliceFeed, "Alice's post")
+	bobPost := store.addPost(bobFeed, "Bob's post")
+	srv := newTestServer(t, store)
+	cookie := login(t, srv, "alice", "correct horse")
+	key := [2]uuid.UUID{alice.ID, post}
+
+	tests := []struct {
+		path  string
+		field string
+		value string
+		state map[[2]uuid.UUID]bool
+		want  bool
+	}{
+		{"read", "read", "true", store.read, true},
+		{"read", "read", "false", store.read, false},
+		{"star", "starred", "true", store.starred, true},
+		{"star", "starred", "false", store.starred, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+"="+tt.value, func(t *testing.T) {
+			rec := do(srv, "POST", "/posts/"+post.String()+"/"+tt.path, url.Values{tt.field: {tt.value}}, cookie)
+			if rec.Code != http.StatusSeeOther {
+				t.Fatalf("status %d, want %d", rec.Code, http.StatusSeeOther)
+			}
+			if got := rec.Header().Get("Location"); got != "/posts/"+post.String() {
+				t.Errorf("redirected to %q", got)
+			}
+			if tt.state[key] != tt.want {
+				t.Errorf("%v = %v, want %v", tt.field, tt.state[key], tt.want)
+			}
+		})
+	}
+
+	for _, path := range []string{"read", "star"} {
+		rec := do(srv, "POST", "/posts/"+bobPost.String()+"/"+path, url.Values{"read": {"true"}, "starred": {"true"}}, cookie)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("POST %v on unfollowed post: status %d, want %d", path, rec.Code, http.StatusNotFound)
+		}
+	}
+	bobKey := [2]uuid.UUID{alice.ID, bobPost}
+	if store.read[bobKey] || store.starred[bobKey] {
+		t.Error("state was saved for a post from an unfollowed feed")
+	}
+}
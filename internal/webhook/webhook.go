@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	EventPostCreated = "post.created"
+
+	SignatureHeader = "X-Gator-Signature"
+	EventHeader     = "X-Gator-Event"
+	DeliveryHeader  = "X-Gator-Delivery"
+)
+
+// MaxAttempts is how many times a delivery is tried before it is marked
+// as failed
+const MaxAttempts = 8
+
+const (
+	firstBackoff = time.Minute
+	maxBackoff   = 6 * time.Hour
+	timeout      = 15 * time.Second
+)
+
+// Payload is the JSON body sent for each new post
+type Payload struct {
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Feed      Feed      `json:"feed"`
+	Post      Post      `json:"post"`
+}
+
+type Feed struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type Post struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	Categories  []string   `json:"categories,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// NewSecret returns a random secret for signing payloads
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign returns the value of the signature header for body: the hex encoded
+// HMAC-SHA256 of the body keyed with the webhook's secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is a valid signature of body, for
+// receivers written in Go
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Send POSTs a signed payload to url. Any response other than 2xx is an
+// error so the delivery is retried.
+func Send(ctx context.Context, url, secret, deliveryID string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("User-Agent", "gator")
+	req.Header.Add(EventHeader, EventPostCreated)
+	req.Header.Add(DeliveryHeader, deliveryID)
+	req.Header.Add(SignatureHeader, Sign(secret, body))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %v", resp.Status)
+	}
+	return nil
+}
+
+// Outcome is what to record after an attempt at a delivery
+type Outcome struct {
+	// Err is nil when the delivery succeeded
+	Err error
+	// NextAttemptAt is when to try again after a failure
+	NextAttemptAt time.Time
+	// GaveUp is set when the delivery failed for the last time
+	GaveUp bool
+}
+
+// Attempt sends a delivery that has failed attempts times before and works
+// out when to retry it if this attempt fails too
+func Attempt(ctx context.Context, url, secret, deliveryID string, body []byte, attempts int, now time.Time) Outcome {
+	err := Send(ctx, url, secret, deliveryID, body)
+	if err == nil {
+		return Outcome{}
+	}
+
+	attempts++
+	return Outcome{
+		Err:           err,
+		NextAttemptAt: now.Add(Backoff(attempts)),
+		GaveUp:        attempts >= MaxAttempts,
+	}
+}
+
+// Backoff returns how long to wait before the next attempt after the
+// given number of failed attempts, doubling each time
+func Backoff(attempts int) time.Duration {
+	backoff := firstBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return backoff
+}
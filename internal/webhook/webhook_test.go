@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendSignsBody(t *testing.T) {
+	const secret = "s3cret"
+	body := []byte(`{"event":"post.created"}`)
+
+	var got http.Header
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	if err := Send(context.Background(), srv.URL, secret, "delivery-1", body); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(gotBody) != string(body) {
+		t.Errorf("body = %q, want %q", gotBody, body)
+	}
+	// printf '%s' '{"event":"post.created"}' | openssl dgst -sha256 -hmac s3cret
+	want := "sha256=37ac0d79bdd285b8dc09f0b9917a0a6ffeb6db5e73ddb2448591a290034ecc43"
+	signature := got.Get(SignatureHeader)
+	if signature != want {
+		t.Errorf("%v = %q, want %q", SignatureHeader, signature, want)
+	}
+	if !Verify(secret, gotBody, signature) {
+		t.Error("Verify rejected the signature that was sent")
+	}
+	if Verify("other", gotBody, signature) {
+		t.Error("Verify accepted a signature made with another secret")
+	}
+	if got.Get(EventHeader) != EventPostCreated {
+		t.Errorf("%v = %q", EventHeader, got.Get(EventHeader))
+	}
+	if got.Get(DeliveryHeader) != "delivery-1" {
+		t.Errorf("%v = %q", DeliveryHeader, got.Get(DeliveryHeader))
+	}
+}
+
+func TestAttemptRetriesWithIncreasingDelay(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2024, 7, 11, 18, 45, 0, 0, time.UTC)
+	var lastDelay time.Duration
+	for attempts := 0; attempts < MaxAttempts; attempts++ {
+		outcome := Attempt(context.Background(), srv.URL, "secret", "delivery-1", []byte("{}"), attempts, now)
+		if outcome.Err == nil {
+			t.Fatalf("attempt %d succeeded against a failing endpoint", attempts+1)
+		}
+
+		delay := outcome.NextAttemptAt.Sub(now)
+		if delay < lastDelay || (delay == lastDelay && delay < maxBackoff) {
+			t.Errorf("attempt %d: delay %v after %v, want it to grow", attempts+1, delay, lastDelay)
+		}
+		if delay > maxBackoff {
+			t.Errorf("attempt %d: delay %v is over the %v cap", attempts+1, delay, maxBackoff)
+		}
+		lastDelay = delay
+
+		if last := attempts+1 == MaxAttempts; outcome.GaveUp != last {
+			t.Errorf("attempt %d: GaveUp = %v, want %v", attempts+1, outcome.GaveUp, last)
+		}
+	}
+	if requests != MaxAttempts {
+		t.Errorf("endpoint got %d requests, want %d", requests, MaxAttempts)
+	}
+}
+
+func TestAttemptDelivered(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	outcome := Attempt(context.Background(), srv.URL, "secret", "delivery-1", []byte("{}"), 3, time.Now())
+	if outcome.Err != nil || outcome.GaveUp {
+		t.Errorf("got %+v, want a successful delivery", outcome)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{8, 128 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, 6 * time.Hour},
+		{50, 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
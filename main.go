@@ -33,6 +33,7 @@ func main() {
 	}
 	dbQueries := database.New(db)
 	state.DB = dbQueries
+	state.Conn = db
 
 	// Initialize commands
 	cmds, err := commands.InitializeCommands()
@@ -0,0 +1,30 @@
+-- name: CreateAlertRule :one
+INSERT INTO alert_rules (id, created_at, updated_at, user_id, feed_id, field, match_type, pattern, action, target)
+VALUES ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10 )
+RETURNING *;
+
+-- name: GetAlertRulesForUser :many
+SELECT alert_rules.*, feeds.name AS feed_name
+FROM alert_rules
+LEFT JOIN feeds ON feeds.id = alert_rules.feed_id
+WHERE alert_rules.user_id = $1
+ORDER BY alert_rules.created_at;
+
+-- name: GetAlertRulesForFeed :many
+-- Command and file actions run as whoever runs agg, so they only come
+-- from that user's own rules or from admins
+SELECT alert_rules.*
+FROM alert_rules
+JOIN feed_follows ON feed_follows.user_id = alert_rules.user_id AND feed_follows.feed_id = sqlc.arg(feed_id)
+JOIN users ON users.id = alert_rules.user_id
+WHERE (alert_rules.feed_id IS NULL OR alert_rules.feed_id = sqlc.arg(feed_id))
+  AND (
+    alert_rules.action NOT IN ('command', 'file')
+    OR alert_rules.user_id = sqlc.arg(runner_id)
+    OR users.role = 'admin'
+  )
+ORDER BY alert_rules.created_at;
+
+-- name: DeleteAlertRule :exec
+DELETE FROM alert_rules
+WHERE id = $1 AND user_id = $2;
@@ -16,13 +16,45 @@ INNER JOIN feeds ON feeds.id = inserted_feed_follow.feed_id
 INNER JOIN users ON users.id = inserted_feed_follow.user_id;
 
 -- name: GetFeedFollowsForUser :many
-SELECT feed_follows.*, feeds.name AS feed_name, users.name AS user_name 
+SELECT feed_follows.*, feeds.name AS feed_name, feeds.url AS feed_url, feeds.site_url AS feed_site_url, users.name AS user_name 
 FROM feed_follows
 INNER JOIN feeds ON feeds.id = feed_follows.feed_id
 INNER JOIN users ON users.id = feed_follows.user_id
-WHERE feed_follows.user_id = $1;
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name;
 
 -- name: DeleteFeedFollow :one
 DELETE FROM feed_follows
 WHERE user_id = $1 AND feed_id = $2
 RETURNING *;
+
+-- name: SetFeedFollowKeepEpisodes :one
+UPDATE feed_follows
+SET keep_episodes = $3, updated_at = $4
+WHERE user_id = $1 AND feed_id = $2
+RETURNING *;
+
+-- name: SetFeedFollowFolder :one
+UPDATE feed_follows
+SET folder = $3, updated_at = $4
+WHERE user_id = $1 AND feed_id = $2
+RETURNING *;
+
+-- name: GetFeedFollowsPageForUser :many
+SELECT feed_follows.*, feeds.name AS feed_name, feeds.url AS feed_url
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name
+LIMIT $2 OFFSET $3;
+
+-- name: CountFollowersForFeed :one
+SELECT count(*) FROM feed_follows
+WHERE feed_id = $1;
+
+-- name: GetAllFeedFollows :many
+SELECT feed_follows.*, feeds.name AS feed_name, feeds.url AS feed_url, feeds.site_url AS feed_site_url, users.name AS user_name
+FROM feed_follows
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+INNER JOIN users ON users.id = feed_follows.user_id
+ORDER BY users.name, feeds.name;
@@ -1,12 +1,15 @@
 -- name: CreateFeed :one
-INSERT INTO feeds ( id, created_at, updated_at, name, url, user_id  ) 
+INSERT INTO feeds ( id, created_at, updated_at, name, url, user_id, site_url, description, image_url  ) 
 VALUES ( 
   $1,
   $2,
   $3,
   $4,
   $5,
-  $6
+  $6,
+  $7,
+  $8,
+  $9
 ) RETURNING *;
 
 -- name: GetFeeds :many
@@ -29,3 +32,65 @@ JOIN feed_follows ff ON f.id = ff.feed_id
 WHERE ff.user_id = $1
 ORDER BY f.last_fetched_at NULLS FIRST
 LIMIT 1;
+
+-- name: RenameFeed :one
+UPDATE feeds
+SET name = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: SetFeedUrl :one
+UPDATE feeds
+SET url = $2, updated_at = $3, last_fetched_at = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = $1;
+
+-- name: MarkOrphanedFeeds :exec
+UPDATE feeds
+SET orphaned_at = $1
+WHERE orphaned_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id);
+
+-- name: ClearFollowedFeedsOrphanedAt :exec
+UPDATE feeds
+SET orphaned_at = NULL
+WHERE orphaned_at IS NOT NULL
+  AND EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id);
+
+-- name: GetOrphanedFeeds :many
+SELECT feeds.*,
+  (SELECT COUNT(*) FROM posts WHERE posts.feed_id = feeds.id) AS post_count,
+  (SELECT COALESCE(SUM(pg_column_size(posts.*)), 0) FROM posts WHERE posts.feed_id = feeds.id)::bigint AS post_bytes
+FROM feeds
+WHERE orphaned_at IS NOT NULL
+ORDER BY orphaned_at, name;
+
+-- name: SetFeedRetention :one
+UPDATE feeds
+SET retention_max_age_hours = $2, retention_max_count = $3, updated_at = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: GetFeedsPage :many
+SELECT feeds.*, users.name AS user_name
+FROM feeds
+LEFT JOIN users ON users.id = feeds.user_id
+ORDER BY feeds.name
+LIMIT $1 OFFSET $2;
+
+-- name: GetFeedByID :one
+SELECT * FROM feeds
+WHERE id = $1;
+
+-- name: CountFeedsForUser :one
+SELECT count(*) FROM feeds
+WHERE user_id = $1;
+
+-- name: TransferFeeds :execrows
+UPDATE feeds
+SET user_id = sqlc.arg(to_user_id), updated_at = sqlc.arg(updated_at)
+WHERE user_id = sqlc.arg(from_user_id);
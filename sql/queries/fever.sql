@@ -0,0 +1,90 @@
+-- name: GetFeverFeedsForUser :many
+SELECT feeds.fever_id, feeds.name, feeds.url, feeds.site_url, feeds.last_fetched_at, feed_follows.folder
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name;
+
+-- name: GetFeverItemsSince :many
+SELECT posts.*,
+  feeds.fever_id AS feed_fever_id,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND posts.fever_id > sqlc.arg(since_id)
+ORDER BY posts.fever_id
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetFeverItemsBefore :many
+SELECT posts.*,
+  feeds.fever_id AS feed_fever_id,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND posts.fever_id < sqlc.arg(max_id)
+ORDER BY posts.fever_id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetFeverItemsByIDs :many
+SELECT posts.*,
+  feeds.fever_id AS feed_fever_id,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND posts.fever_id = ANY(sqlc.arg(ids)::bigint[])
+ORDER BY posts.fever_id;
+
+-- name: CountPostsForUser :one
+SELECT COUNT(*) FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1;
+
+-- name: GetUnreadFeverIDsForUser :many
+SELECT posts.fever_id
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND NOT COALESCE(post_states.read, false)
+ORDER BY posts.fever_id;
+
+-- name: GetStarredFeverIDsForUser :many
+SELECT posts.fever_id
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+  AND post_states.starred
+ORDER BY posts.fever_id;
+
+-- name: GetPostIDByFeverIDForUser :one
+SELECT posts.id
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+  AND posts.fever_id = $2;
+
+-- name: MarkPostsReadForUser :execrows
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read)
+SELECT feed_follows.user_id, posts.id, sqlc.arg(now), sqlc.arg(now), true
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND posts.created_at < sqlc.arg(before)
+  AND (sqlc.narg(feed_fever_id)::bigint IS NULL OR feeds.fever_id = sqlc.narg(feed_fever_id))
+  AND (sqlc.narg(folder)::text IS NULL OR feed_follows.folder = sqlc.narg(folder))
+ON CONFLICT (user_id, post_id)
+DO UPDATE SET read = true, updated_at = EXCLUDED.updated_at;
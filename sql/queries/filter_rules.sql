@@ -0,0 +1,29 @@
+-- name: CreateFilterRule :one
+INSERT INTO filter_rules (id, created_at, updated_at, user_id, feed_id, action, field, match_type, pattern, at_ingest)
+VALUES ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10 )
+RETURNING *;
+
+-- name: GetFilterRulesForUser :many
+SELECT filter_rules.*, feeds.name AS feed_name
+FROM filter_rules
+LEFT JOIN feeds ON feeds.id = filter_rules.feed_id
+WHERE filter_rules.user_id = $1
+ORDER BY filter_rules.created_at;
+
+-- name: GetFilterRulesToApply :many
+-- The user's rules without the feed names GetFilterRulesForUser lists
+SELECT * FROM filter_rules
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: DeleteFilterRule :exec
+DELETE FROM filter_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetIngestFilterRules :many
+-- Ingest rules drop posts for everyone, so only admins' rules count
+SELECT filter_rules.*
+FROM filter_rules
+JOIN users ON users.id = filter_rules.user_id
+WHERE filter_rules.at_ingest AND users.role = 'admin'
+ORDER BY filter_rules.created_at;
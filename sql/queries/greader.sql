@@ -0,0 +1,24 @@
+-- name: GetReaderItems :many
+SELECT posts.*,
+  feeds.fever_id AS feed_fever_id,
+  feeds.name AS feed_name,
+  feeds.site_url AS feed_site_url,
+  feed_follows.folder,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(ids)::bigint[] IS NULL OR posts.fever_id = ANY(sqlc.narg(ids)::bigint[]))
+  AND (sqlc.narg(feed_fever_id)::bigint IS NULL OR feeds.fever_id = sqlc.narg(feed_fever_id))
+  AND (sqlc.narg(folder)::text IS NULL OR feed_follows.folder = sqlc.narg(folder))
+  AND (NOT sqlc.arg(starred_only)::boolean OR COALESCE(post_states.starred, false))
+  AND (NOT sqlc.arg(read_only)::boolean OR COALESCE(post_states.read, false))
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT COALESCE(post_states.read, false))
+  AND (sqlc.narg(newer_than)::timestamp IS NULL OR posts.created_at > sqlc.narg(newer_than))
+ORDER BY
+  CASE WHEN sqlc.arg(oldest_first)::boolean THEN posts.fever_id END ASC,
+  posts.fever_id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
@@ -0,0 +1,18 @@
+-- name: CreatePostEnclosure :one
+INSERT INTO post_enclosures (id, created_at, updated_at, post_id, url, type, length)
+VALUES ( $1, $2, $3, $4, $5, $6, $7 )
+RETURNING *;
+
+-- name: GetEnclosuresForPost :many
+SELECT * FROM post_enclosures
+WHERE post_id = $1
+ORDER BY created_at;
+
+-- name: GetEpisodesForFeed :many
+SELECT post_enclosures.*, posts.title AS post_title, posts.published_at AS post_published_at
+FROM post_enclosures
+JOIN posts ON posts.id = post_enclosures.post_id
+WHERE posts.feed_id = $1
+  AND (post_enclosures.type IS NULL OR post_enclosures.type LIKE 'audio/%' OR post_enclosures.type LIKE 'video/%')
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT $2;
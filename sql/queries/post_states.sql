@@ -0,0 +1,11 @@
+-- name: SetPostRead :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read)
+VALUES ( $1, $2, $3, $3, $4 )
+ON CONFLICT (user_id, post_id)
+DO UPDATE SET read = EXCLUDED.read, updated_at = EXCLUDED.updated_at;
+
+-- name: SetPostStarred :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, starred)
+VALUES ( $1, $2, $3, $3, $4 )
+ON CONFLICT (user_id, post_id)
+DO UPDATE SET starred = EXCLUDED.starred, updated_at = EXCLUDED.updated_at;
@@ -7,14 +7,159 @@ INSERT INTO posts (
   url,
   description,
   published_at,
-  feed_id
-) VALUES ( $1, $2, $3, $4, $5, $6, $7, $8 )
+  feed_id,
+  content,
+  author,
+  categories,
+  comments_url,
+  duration_seconds,
+  episode,
+  season,
+  image_url
+) VALUES ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16 )
 RETURNING *;
 
 -- name: GetPostsForUser :many
 SELECT posts.* FROM posts
 JOIN feed_follows on posts.feed_id = feed_follows.feed_id
 WHERE feed_follows.user_id = $1
-ORDER BY published_at DESC
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
 LIMIT $2;
 
+
+-- name: GetPostsWithStateForUser :many
+SELECT posts.*,
+  feeds.name AS feed_name,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: CountPostsForFeed :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = $1;
+
+-- name: PrunePostsForFeed :one
+WITH pruned AS (
+  DELETE FROM posts
+  WHERE posts.feed_id = sqlc.arg(feed_id)
+    AND NOT EXISTS (
+      SELECT 1 FROM post_states
+      WHERE post_states.post_id = posts.id AND post_states.starred
+    )
+    AND (
+      COALESCE(posts.published_at, posts.created_at) < sqlc.narg(published_before)::timestamp
+      OR (
+        sqlc.narg(keep_count)::integer IS NOT NULL
+        AND posts.id NOT IN (
+          SELECT newest.id FROM posts newest
+          WHERE newest.feed_id = sqlc.arg(feed_id)
+          ORDER BY COALESCE(newest.published_at, newest.created_at) DESC
+          LIMIT sqlc.narg(keep_count)
+        )
+      )
+    )
+  RETURNING posts.url, posts.feed_id
+), tombstones AS (
+  INSERT INTO pruned_posts (url, feed_id, pruned_at)
+  SELECT pruned.url, pruned.feed_id, sqlc.arg(pruned_at) FROM pruned
+  ON CONFLICT (url) DO UPDATE SET pruned_at = EXCLUDED.pruned_at
+)
+SELECT count(*) FROM pruned;
+
+-- name: IsPostPruned :one
+SELECT EXISTS (
+  SELECT 1 FROM pruned_posts
+  WHERE url = $1
+);
+
+-- name: GetPostsForUserInFolder :many
+SELECT posts.* FROM posts
+JOIN feed_follows on posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+  AND (feed_follows.folder = sqlc.arg(folder)::text OR starts_with(feed_follows.folder, sqlc.arg(folder)::text || '/'))
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT $2;
+
+-- name: GetUnreadPostsForDigest :many
+-- The limit keeps the oldest posts, so a capped digest can move its
+-- watermark to the last post it includes
+WITH oldest AS (
+  SELECT posts.*, feeds.name AS feed_name
+  FROM posts
+  JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+  JOIN feeds ON feeds.id = posts.feed_id
+  LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+  WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND posts.created_at > sqlc.arg(since)
+    AND posts.created_at <= sqlc.arg(until)
+    AND NOT COALESCE(post_states.read, false)
+  ORDER BY posts.created_at
+  LIMIT sqlc.arg(row_limit)
+)
+SELECT * FROM oldest
+ORDER BY oldest.feed_name, COALESCE(oldest.published_at, oldest.created_at) DESC;
+
+-- name: GetTimelineForUser :many
+SELECT posts.*, feeds.name AS feed_name, feeds.url AS feed_url
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND (
+    sqlc.narg(folder)::text IS NULL
+    OR feed_follows.folder = sqlc.narg(folder)::text
+    OR starts_with(feed_follows.folder, sqlc.narg(folder)::text || '/')
+  )
+  AND (NOT sqlc.arg(starred_only)::boolean OR COALESCE(post_states.starred, false))
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetUnreadPostsForUser :many
+SELECT posts.*,
+  feeds.name AS feed_name,
+  false AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND NOT COALESCE(post_states.read, false)
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetPostWithStateForUser :one
+SELECT posts.*,
+  feeds.name AS feed_name,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND posts.id = sqlc.arg(post_id);
+
+-- name: GetPostsPageForUser :many
+SELECT posts.*,
+  feeds.name AS feed_name,
+  COALESCE(post_states.read, false)::boolean AS read,
+  COALESCE(post_states.starred, false)::boolean AS starred
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT COALESCE(post_states.read, false))
+  AND (NOT sqlc.arg(starred_only)::boolean OR COALESCE(post_states.starred, false))
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC, posts.id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
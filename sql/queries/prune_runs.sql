@@ -0,0 +1,16 @@
+-- name: CreatePruneRun :one
+INSERT INTO prune_runs (id, created_at, removed_posts)
+VALUES ( $1, $2, $3 )
+RETURNING *;
+
+-- name: GetRetentionDefaults :one
+SELECT * FROM retention_defaults;
+
+-- name: SetRetentionDefaults :one
+INSERT INTO retention_defaults (id, max_age_hours, max_count, updated_at)
+VALUES ( TRUE, $1, $2, $3 )
+ON CONFLICT (id) DO UPDATE
+SET max_age_hours = EXCLUDED.max_age_hours,
+    max_count = EXCLUDED.max_count,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
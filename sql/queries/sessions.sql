@@ -0,0 +1,16 @@
+-- name: CreateSession :one
+INSERT INTO sessions (id, created_at, expires_at, user_id, token_hash)
+VALUES ( $1, $2, $3, $4, $5 )
+RETURNING *;
+
+-- name: GetSessionByHash :one
+SELECT * FROM sessions
+WHERE token_hash = $1 AND expires_at > $2;
+
+-- name: DeleteSessionByHash :exec
+DELETE FROM sessions
+WHERE token_hash = $1;
+
+-- name: DeleteSessionsForUser :exec
+DELETE FROM sessions
+WHERE user_id = $1;
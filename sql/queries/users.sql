@@ -1,10 +1,12 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, password_hash, role)
 VALUES (
   $1,
   $2,
   $3,
-  $4
+  $4,
+  $5,
+  $6
 )
 RETURNING *;
 
@@ -21,3 +23,67 @@ SELECT * FROM users;
 
 -- name: ResetUsers :exec
 DELETE FROM users;
+
+-- name: SetUserEmail :one
+UPDATE users
+SET email = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: SetUserLastDigestAt :exec
+UPDATE users
+SET last_digest_at = $2
+WHERE id = $1;
+
+-- name: GetUsersPage :many
+SELECT * FROM users
+ORDER BY name
+LIMIT $1 OFFSET $2;
+
+-- name: SetUserFeverAPIKey :exec
+UPDATE users
+SET fever_api_key = $2, updated_at = $3
+WHERE id = $1;
+
+-- name: GetUserByFeverAPIKey :one
+SELECT * FROM users
+WHERE fever_api_key = $1;
+
+-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $2, updated_at = $3
+WHERE id = $1;
+
+-- name: CountUsers :one
+SELECT count(*) FROM users;
+
+-- name: CountAdmins :one
+SELECT count(*) FROM users
+WHERE role = 'admin';
+
+-- name: SetUserRole :exec
+UPDATE users
+SET role = $2, updated_at = $3
+WHERE id = $1;
+
+-- name: GetResetSummary :one
+SELECT
+  (SELECT count(*) FROM users) AS user_count,
+  (SELECT count(*) FROM feeds) AS feed_count,
+  (SELECT count(*) FROM feed_follows) AS follow_count,
+  (SELECT count(*) FROM posts) AS post_count;
+
+-- name: RenameUser :one
+-- The Fever API key is derived from the username, so it stops working
+UPDATE users
+SET name = $2, fever_api_key = NULL, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1;
+
+-- name: CountUsersWithPassword :one
+SELECT count(*) FROM users
+WHERE password_hash IS NOT NULL;
@@ -0,0 +1,66 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, created_at, updated_at, user_id, feed_id, url, secret, global)
+VALUES ( $1, $2, $3, $4, $5, $6, $7, $8 )
+RETURNING *;
+
+-- name: GetWebhooksForUser :many
+SELECT webhooks.*, feeds.name AS feed_name
+FROM webhooks
+LEFT JOIN feeds ON feeds.id = webhooks.feed_id
+WHERE webhooks.user_id = $1
+ORDER BY webhooks.created_at;
+
+-- name: GetWebhooksForFeed :many
+SELECT webhooks.*
+FROM webhooks
+WHERE (webhooks.feed_id IS NULL OR webhooks.feed_id = sqlc.arg(feed_id))
+AND (
+  webhooks.global
+  OR EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.user_id = webhooks.user_id AND feed_follows.feed_id = sqlc.arg(feed_id)
+  )
+)
+ORDER BY webhooks.created_at;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (id, created_at, updated_at, webhook_id, post_id, payload, next_attempt_at)
+VALUES ( $1, $2, $3, $4, $5, $6, $7 )
+RETURNING *;
+
+-- name: GetDueWebhookDeliveries :many
+SELECT webhook_deliveries.*, webhooks.url AS webhook_url, webhooks.secret AS webhook_secret
+FROM webhook_deliveries
+JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+WHERE webhook_deliveries.delivered_at IS NULL
+AND webhook_deliveries.failed_at IS NULL
+AND webhook_deliveries.next_attempt_at <= $1
+ORDER BY webhook_deliveries.next_attempt_at
+LIMIT $2;
+
+-- name: MarkWebhookDelivered :exec
+UPDATE webhook_deliveries
+SET attempts = attempts + 1, delivered_at = $2, updated_at = $2, last_error = NULL
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3, failed_at = $4, updated_at = $5
+WHERE id = $1;
+
+-- name: GetFailedWebhookDeliveriesForUser :many
+SELECT webhook_deliveries.*, webhooks.url AS webhook_url, posts.title AS post_title
+FROM webhook_deliveries
+JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+JOIN posts ON posts.id = webhook_deliveries.post_id
+WHERE webhooks.user_id = $1 AND webhook_deliveries.failed_at IS NOT NULL
+ORDER BY webhook_deliveries.failed_at DESC;
+
+-- name: RetryWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET attempts = 0, failed_at = NULL, next_attempt_at = $2, updated_at = $2
+WHERE id = $1 AND failed_at IS NOT NULL;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE post_states (
+  user_id UUID NOT NULL,
+  post_id UUID NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  read BOOLEAN NOT NULL DEFAULT false,
+  starred BOOLEAN NOT NULL DEFAULT false,
+  PRIMARY KEY (user_id, post_id),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE post_states;
@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN duration_seconds INTEGER,
+ADD COLUMN episode INTEGER,
+ADD COLUMN season INTEGER,
+ADD COLUMN image_url TEXT;
+
+ALTER TABLE feed_follows
+ADD COLUMN keep_episodes INTEGER;
+
+-- +goose Down
+ALTER TABLE feed_follows
+DROP COLUMN keep_episodes;
+
+ALTER TABLE posts
+DROP COLUMN duration_seconds,
+DROP COLUMN episode,
+DROP COLUMN season,
+DROP COLUMN image_url;
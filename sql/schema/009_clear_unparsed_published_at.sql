@@ -0,0 +1,8 @@
+-- +goose Up
+-- Posts whose date could not be parsed used to be stored with the zero time
+UPDATE posts
+SET published_at = NULL
+WHERE published_at < '1900-01-01';
+
+-- +goose Down
+SELECT 1;
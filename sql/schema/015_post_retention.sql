@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN retention_max_age_hours INTEGER,
+ADD COLUMN retention_max_count INTEGER;
+
+CREATE TABLE prune_runs (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  removed_posts INTEGER NOT NULL
+);
+
+-- +goose Down
+DROP TABLE prune_runs;
+
+ALTER TABLE feeds
+DROP COLUMN retention_max_age_hours,
+DROP COLUMN retention_max_count;
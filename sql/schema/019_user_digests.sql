@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN email TEXT,
+ADD COLUMN last_digest_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN email,
+DROP COLUMN last_digest_at;
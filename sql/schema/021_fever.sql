@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN fever_id BIGSERIAL NOT NULL UNIQUE;
+ALTER TABLE posts ADD COLUMN fever_id BIGSERIAL NOT NULL UNIQUE;
+ALTER TABLE users ADD COLUMN fever_api_key TEXT UNIQUE;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN fever_api_key;
+ALTER TABLE posts DROP COLUMN fever_id;
+ALTER TABLE feeds DROP COLUMN fever_id;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Pruned posts are remembered so the next fetch doesn't add them again
+CREATE TABLE pruned_posts (
+  url TEXT PRIMARY KEY,
+  feed_id UUID NOT NULL,
+  pruned_at TIMESTAMP NOT NULL,
+  FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+-- The default retention policy, a single row shared by every user
+CREATE TABLE retention_defaults (
+  id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+  max_age_hours INTEGER,
+  max_count INTEGER,
+  updated_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE retention_defaults;
+DROP TABLE pruned_posts;